@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"quards/internal/cards"
+	"quards/internal/database"
+	"quards/internal/lens/services"
+)
+
+func main() {
+	// Load .env file (ignore error if file doesn't exist)
+	_ = godotenv.Load()
+
+	var (
+		help   bool
+		input  string
+		format string
+		output string
+		dryRun bool
+		force  bool
+		quiet  bool
+		toDB   bool
+		lang   string
+	)
+	flag.BoolVar(&help, "help", false, "Show help message")
+	flag.BoolVar(&help, "h", false, "Show help message (shorthand)")
+	flag.StringVar(&input, "in", "", "Card dump file to import (required)")
+	flag.StringVar(&format, "format", cards.FormatLorcanaAPI, "Format of the card dump")
+	flag.StringVar(&output, "out", services.CardsPath(), "Canonical card file to update")
+	flag.BoolVar(&dryRun, "dry-run", false, "Report changes without writing")
+	flag.BoolVar(&force, "force", false, "Write even if cards would be removed")
+	flag.BoolVar(&quiet, "q", false, "Only print the summary")
+	flag.BoolVar(&toDB, "db", false, "Update the cards table instead of the card file")
+	flag.StringVar(&lang, "lang", "", "Import the dump as translations for this language (e.g. de)")
+	flag.Parse()
+
+	if help || input == "" {
+		showHelp()
+		if !help {
+			os.Exit(2)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", input, err)
+	}
+
+	incoming, err := cards.Import(format, data)
+	if err != nil {
+		log.Fatalf("Failed to import %s: %v", input, err)
+	}
+
+	if lang == "" {
+		if problems := cards.Validate(incoming); len(problems) > 0 {
+			failValidation(problems, input)
+		}
+	} else if err := cards.ValidateLanguage(lang); err != nil {
+		log.Fatal(err)
+	}
+
+	var current []cards.Record
+	if toDB {
+		if err := database.InitDB(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer database.CloseDB()
+
+		output = "cards table"
+		current, err = cards.ReadTable(database.GetDB())
+	} else {
+		current, err = cards.ReadFile(output)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", output, err)
+	}
+
+	// Translations are merged into the existing cards rather than replacing them
+	if lang != "" {
+		merged, problems := cards.MergeTranslations(current, lang, incoming)
+		if len(problems) > 0 {
+			failValidation(problems, input)
+		}
+		incoming = merged
+	}
+
+	report := cards.Diff(current, incoming)
+	printReport(report, quiet)
+
+	if !report.HasChanges() {
+		fmt.Println("Card data is up to date.")
+		return
+	}
+	if dryRun {
+		fmt.Println("Dry run: no changes written.")
+		return
+	}
+	if len(report.Removed) > 0 && !force {
+		log.Fatalf("Refusing to remove %d card(s) without -force", len(report.Removed))
+	}
+
+	if toDB {
+		err = cards.WriteTable(database.GetDB(), incoming, report)
+	} else {
+		err = cards.WriteFile(output, incoming)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write %s: %v", output, err)
+	}
+	fmt.Printf("Wrote %d cards to %s\n", len(incoming), output)
+}
+
+func failValidation(problems []cards.Problem, input string) {
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  [%d] %s: %s\n", problem.Index, problem.CardID, problem.Detail)
+	}
+	log.Fatalf("Validation failed: %d problem(s) in %s", len(problems), input)
+}
+
+func printReport(report *cards.Report, quiet bool) {
+	fmt.Printf("Added: %d, removed: %d, changed: %d\n", len(report.Added), len(report.Removed), len(report.Changed))
+	if quiet {
+		return
+	}
+	for _, id := range report.Added {
+		fmt.Printf("  + %s\n", id)
+	}
+	for _, id := range report.Removed {
+		fmt.Printf("  - %s\n", id)
+	}
+	for _, change := range report.Changed {
+		fmt.Printf("  ~ %s (%s): %s\n", change.CardID, change.Name, strings.Join(change.Fields, ", "))
+	}
+}
+
+func showHelp() {
+	fmt.Printf(`Card Data Tool for Quards
+
+Imports a card dump, validates it, reports added, removed and changed cards,
+and writes the canonical card file used by the server, lenses and frontend.
+Runs fully offline from the provided file.
+
+Usage: %s -in <file> [options]
+
+Options:
+  -in FILE        Card dump file to import (required)
+  -format NAME    Format of the card dump (available: %s)
+  -out FILE       Canonical card file to update (default: %s)
+  -dry-run        Report changes without writing
+  -force          Write even if cards would be removed
+  -q              Only print the summary
+  -db             Update the cards table instead of the card file
+  -lang CODE      Import the dump as translated names and text for a language
+  -h, -help       Show this help message
+
+Environment Variables:
+  CARDS_PATH      Canonical card file used by the server (default: %s)
+  DATABASE_URL    Database connection string (with -db)
+
+Examples:
+  # Preview an update from a downloaded lorcana-api bulk dump
+  curl -o cards-bulk.json https://api.lorcana-api.com/bulk/cards
+  %s -in cards-bulk.json -dry-run
+
+  # Apply it
+  %s -in cards-bulk.json
+
+  # Load it into the cards table (servers with CARDS_SOURCE=database reload automatically)
+  %s -in cards-bulk.json -db
+
+  # Add German names and text from a dump with the same Unique_IDs
+  %s -in cards-de.json -lang de
+
+`, os.Args[0], strings.Join(cards.Formats(), ", "), services.CardsPath(), services.DefaultCardsPath, os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"quards/internal/bot"
+	"quards/internal/database"
+	"quards/internal/deck"
+	"quards/internal/game"
+	"quards/internal/lens/services"
+	"quards/internal/simulation"
+)
+
+// cardSetItem matches one entry of an inline card ID set: CARD-ID or CARD-ID:count
+var cardSetItem = regexp.MustCompile(`^([A-Za-z0-9]+-[A-Za-z0-9]+)(?::([0-9]+))?$`)
+
+func main() {
+	// Load .env file (ignore error if file doesn't exist)
+	_ = godotenv.Load()
+
+	var (
+		help     bool
+		deckA    string
+		deckB    string
+		botA     string
+		botB     string
+		games    int
+		seed     int64
+		workers  int
+		maxTurns int
+		persist  bool
+		verbose  bool
+		asJSON   bool
+	)
+	flag.BoolVar(&help, "help", false, "Show help message")
+	flag.BoolVar(&help, "h", false, "Show help message (shorthand)")
+	flag.StringVar(&deckA, "a", "", "Deck A: deck file, card ID set, or deck name or ID (required)")
+	flag.StringVar(&deckB, "b", "", "Deck B: deck file, card ID set, or deck name or ID (required)")
+	flag.StringVar(&botA, "bot-a", bot.KindGreedy, "Bot playing deck A")
+	flag.StringVar(&botB, "bot-b", bot.KindGreedy, "Bot playing deck B")
+	flag.IntVar(&games, "games", 100, "Number of games to play")
+	flag.Int64Var(&seed, "seed", 1, "Base seed the games are drawn from")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "Games played in parallel")
+	flag.IntVar(&maxTurns, "max-turns", simulation.DefaultMaxTurns, "Turn after which a game is a draw")
+	flag.BoolVar(&persist, "persist", false, "Save every game to the database")
+	flag.BoolVar(&verbose, "v", false, "Print every game's result")
+	flag.BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	flag.Parse()
+
+	if help || deckA == "" || deckB == "" {
+		showHelp()
+		if !help {
+			os.Exit(2)
+		}
+		return
+	}
+	if games < 1 {
+		log.Fatalf("-games must be at least 1")
+	}
+
+	cardDB := services.NewInMemoryCardDB()
+	if err := cardDB.LoadFromFile(services.CardsPath()); err != nil {
+		log.Fatalf("Failed to load cards: %v", err)
+	}
+
+	// The database is only needed for saved decks and for saving games
+	if persist || !isOffline(deckA) || !isOffline(deckB) {
+		if err := database.InitDB(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer database.CloseDB()
+	}
+
+	var decks [2]*deck.DeckVersion
+	for i, identifier := range []string{deckA, deckB} {
+		loaded, err := loadDeck(identifier, cardDB)
+		if err != nil {
+			log.Fatalf("Failed to load deck %s: %v", identifier, err)
+		}
+		decks[i] = loaded
+	}
+
+	config := simulation.Config{
+		Decks:    decks,
+		Bots:     [2]string{botA, botB},
+		Games:    games,
+		Seed:     seed,
+		Workers:  workers,
+		MaxTurns: maxTurns,
+		KeepLogs: persist,
+		Services: &services.LensServices{CardDB: cardDB, Cache: services.NewInMemoryCache()},
+	}
+
+	started := time.Now()
+	results, err := simulation.Run(config)
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+	elapsed := time.Since(started)
+
+	// Games are saved in order after the run so their IDs follow the game numbers
+	if persist {
+		for i := range results {
+			result := &results[i]
+			if result.Err != nil {
+				continue
+			}
+			player1Deck, player2Deck := decks[result.FirstDeck], decks[1-result.FirstDeck]
+			if _, err := game.RecordGame(player1Deck, player2Deck, result.Seed, result.Log, result.Winner, result.Turns); err != nil {
+				log.Fatalf("Failed to save game %d: %v", result.Index, err)
+			}
+		}
+	}
+
+	report := simulation.Summarize(results)
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	} else {
+		if verbose {
+			printGames(results, decks)
+		}
+		printReport(report, &config, elapsed)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "game %d (seed %d): %v\n", result.Index, result.Seed, result.Err)
+		}
+	}
+}
+
+// isOffline reports whether a deck identifier can be loaded without the database
+func isOffline(identifier string) bool {
+	if strings.HasSuffix(identifier, ".json") {
+		return true
+	}
+	_, ok := parseCardSet(identifier)
+	return ok
+}
+
+// loadDeck loads a deck from a deck file, an inline card ID set, or the
+// latest saved version of a deck in the database
+func loadDeck(identifier string, cardDB services.CardDatabase) (*deck.DeckVersion, error) {
+	var loaded *deck.DeckVersion
+	if strings.HasSuffix(identifier, ".json") {
+		data, err := os.ReadFile(identifier)
+		if err != nil {
+			return nil, err
+		}
+		loaded = &deck.DeckVersion{}
+		if err := json.Unmarshal(data, loaded); err != nil {
+			return nil, fmt.Errorf("failed to parse deck file: %w", err)
+		}
+	} else if cards, ok := parseCardSet(identifier); ok {
+		loaded = &deck.DeckVersion{Name: identifier, Cards: cards}
+	} else {
+		return game.ResolveDeckVersion(identifier)
+	}
+
+	loaded.CardCount = 0
+	for cardID, count := range loaded.Cards {
+		if _, exists := cardDB.GetCard(cardID); !exists {
+			return nil, fmt.Errorf("unknown card %s", cardID)
+		}
+		loaded.CardCount += count
+	}
+	if loaded.CardCount < 7 {
+		return nil, fmt.Errorf("deck has %d cards, fewer than an opening hand", loaded.CardCount)
+	}
+	return loaded, nil
+}
+
+// parseCardSet parses an inline deck such as "INK-069:4,INK-071:2", where a
+// card without a count is a single copy
+func parseCardSet(identifier string) (map[string]int, bool) {
+	if !strings.ContainsAny(identifier, ",:") {
+		return nil, false
+	}
+
+	cards := make(map[string]int)
+	for _, item := range strings.Split(identifier, ",") {
+		match := cardSetItem.FindStringSubmatch(strings.TrimSpace(item))
+		if match == nil {
+			return nil, false
+		}
+		count := 1
+		if match[2] != "" {
+			count, _ = strconv.Atoi(match[2])
+		}
+		cards[match[1]] += count
+	}
+	return cards, true
+}
+
+func printGames(results []simulation.GameResult, decks [2]*deck.DeckVersion) {
+	for _, result := range results {
+		outcome := "draw"
+		if result.Err != nil {
+			outcome = "error"
+		} else if winner := result.WinningDeck(); winner >= 0 {
+			outcome = decks[winner].Name + " won"
+		}
+		fmt.Printf("  game %d: seed %d, %s first, %d turns, %s\n",
+			result.Index, result.Seed, decks[result.FirstDeck].Name, result.Turns, outcome)
+	}
+}
+
+func printReport(report *simulation.Report, config *simulation.Config, elapsed time.Duration) {
+	fmt.Printf("%s (%s) vs %s (%s)\n", config.Decks[0].Name, config.Bots[0], config.Decks[1].Name, config.Bots[1])
+	fmt.Printf("Games: %d (seed %d, %d workers, %s)\n", report.Games, config.Seed, config.Workers, elapsed.Round(time.Millisecond))
+	if report.Errors > 0 {
+		fmt.Printf("Errors: %d\n", report.Errors)
+	}
+	fmt.Printf("Wins: %d - %d, draws: %d\n", report.Wins[0], report.Wins[1], report.Draws)
+	fmt.Printf("%s win rate: %s\n", config.Decks[0].Name, formatRate(report.WinRate))
+	fmt.Printf("First player win rate: %s\n", formatRate(report.FirstPlayer))
+	fmt.Printf("Average game length: %.1f turns\n", report.AverageTurns)
+}
+
+func formatRate(rate simulation.Rate) string {
+	return fmt.Sprintf("%.1f%% (95%% CI %.1f%% - %.1f%%, %d/%d)",
+		rate.Rate*100, rate.Low*100, rate.High*100, rate.Count, rate.Total)
+}
+
+func showHelp() {
+	fmt.Printf(`Matchup Simulator for Quards
+
+Plays bot against bot games between two decks in-process, without the
+server, and reports deck A's win rate with a 95%% confidence interval, the
+first player's win rate and the average game length. The decks take turns
+playing first. Every game is drawn from the base seed, so the same options
+give the same results.
+
+Usage: %s -a <deck> -b <deck> [options]
+
+Decks are given as a deck file (decks/*.json), an inline card ID set
+("INK-069:4,INK-071:2,..."), or the name or ID of a saved deck, which plays
+its latest version.
+
+Options:
+  -a DECK         Deck A (required)
+  -b DECK         Deck B (required)
+  -bot-a KIND     Bot playing deck A (default: %s; available: %s)
+  -bot-b KIND     Bot playing deck B (default: %s)
+  -games N        Number of games to play (default: 100)
+  -seed N         Base seed the games are drawn from (default: 1)
+  -workers N      Games played in parallel (default: number of CPUs)
+  -max-turns N    Turn after which a game is a draw (default: %d)
+  -persist        Save every game, with its winner and length, to the database
+  -v              Print every game's result
+  -json           Print the report as JSON
+  -h, -help       Show this help message
+
+Environment Variables:
+  CARDS_PATH      Card file the games are played with (default: %s)
+  DATABASE_URL    Database connection string (for saved decks and -persist)
+
+Examples:
+  # Greedy against greedy with the bundled decks
+  %s -a decks/A_UG_Pile_of_stuff.json -b decks/Some_Random_RY_Deck.json
+
+  # Search bot against a random bot, 1000 games from seed 42
+  %s -a decks/A_UG_Pile_of_stuff.json -b decks/Some_Random_RY_Deck.json -bot-a search -bot-b random -games 1000 -seed 42
+
+  # Saved decks, keeping the games for replay
+  %s -a "My Deck" -b 12 -persist
+
+`, os.Args[0], bot.KindGreedy, strings.Join(bot.Kinds, ", "), bot.KindGreedy, simulation.DefaultMaxTurns,
+		services.DefaultCardsPath, os.Args[0], os.Args[0], os.Args[0])
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"quards/internal/auth"
+	"quards/internal/deck"
+)
+
+// ListUsersHandler returns all users
+func ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := auth.ListUsers()
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to list users: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, users)
+}
+
+// DeactivateUserHandler deactivates a user and ends their sessions
+func DeactivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if userID == auth.GetUserIDFromContext(r) {
+		writeError(w, "cannot deactivate your own account", http.StatusBadRequest)
+		return
+	}
+
+	if err := auth.SetUserActive(userID, false); err != nil {
+		writeError(w, fmt.Sprintf("failed to deactivate user: %v", err), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, map[string]string{"message": "user deactivated successfully"})
+}
+
+// ReactivateUserHandler reactivates a previously deactivated user
+func ReactivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := auth.SetUserActive(userID, true); err != nil {
+		writeError(w, fmt.Sprintf("failed to reactivate user: %v", err), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, map[string]string{"message": "user reactivated successfully"})
+}
+
+// UpdateUserRoleRequest represents a request to change a user's role
+type UpdateUserRoleRequest struct {
+	Role auth.Role `json:"role"`
+}
+
+// UpdateUserRoleHandler changes a user's role
+func UpdateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if !req.Role.IsValid() {
+		writeError(w, fmt.Sprintf("invalid role: %s", req.Role), http.StatusBadRequest)
+		return
+	}
+
+	if userID == auth.GetUserIDFromContext(r) && req.Role != auth.RoleAdmin {
+		writeError(w, "cannot remove your own admin role", http.StatusBadRequest)
+		return
+	}
+
+	if err := auth.SetUserRole(userID, req.Role); err != nil {
+		writeError(w, fmt.Sprintf("failed to update user role: %v", err), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, map[string]string{"message": "user role updated successfully"})
+}
+
+// TransferDeckRequest represents a request to move a deck to another user
+type TransferDeckRequest struct {
+	UserID int `json:"userId"`
+}
+
+// TransferDeckHandler transfers ownership of a deck to another user
+func TransferDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TransferDeckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := auth.LoadUserByID(req.UserID); err != nil {
+		writeError(w, fmt.Sprintf("failed to load user: %v", err), http.StatusNotFound)
+		return
+	}
+
+	if err := deck.TransferDeckOwnership(deckID, req.UserID); err != nil {
+		writeError(w, fmt.Sprintf("failed to transfer deck: %v", err), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, map[string]string{"message": "deck transferred successfully"})
+}
@@ -1,11 +1,18 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 
+	"quards/internal/auth"
 	"quards/internal/database"
+	"quards/internal/format"
+	"quards/internal/lens"
+	"quards/internal/lens/services"
+	"quards/internal/overlay"
 
 	"github.com/gorilla/mux"
 )
@@ -16,31 +23,107 @@ func Run() error {
 	}
 	defer database.CloseDB()
 
+	if _, err := format.LoadDir(format.DefaultDir); err != nil {
+		return fmt.Errorf("load formats: %w", err)
+	}
+
+	cardDB, err := openCardDB()
+	if err != nil {
+		return fmt.Errorf("load cards: %w", err)
+	}
+	lens.SetDefaultCardDB(cardDB)
+	lensProcessor = lens.New()
+	fmt.Printf("Loaded %d cards\n", cardDB.Count())
+
+	// Reloads clear the processor's cache, so it must exist before listening
+	if postgresCardDB, ok := cardDB.(*services.PostgresCardDB); ok {
+		go listenForCardChanges(postgresCardDB)
+	}
+
 	r := mux.NewRouter()
 	apiRouter := r.PathPrefix("/api").Subrouter()
+	authMiddleware := auth.NewAuthMiddleware()
 
+	// Authentication endpoints
+	apiRouter.HandleFunc("/auth/discord/login", LoginDiscordHandler).Methods("GET")
+	apiRouter.HandleFunc("/auth/discord/callback", CallbackDiscordHandler).Methods("GET")
+	apiRouter.HandleFunc("/auth/logout", LogoutHandler).Methods("POST")
+	apiRouter.Handle("/auth/me", authMiddleware.RequireAuth(http.HandlerFunc(MeHandler))).Methods("GET")
+
+	// Admin endpoints: every route on this subrouter requires the admin role
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	adminRouter.HandleFunc("/users", ListUsersHandler).Methods("GET")
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/deactivate", DeactivateUserHandler).Methods("POST")
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/reactivate", ReactivateUserHandler).Methods("POST")
+	adminRouter.HandleFunc("/users/{id:[0-9]+}/role", UpdateUserRoleHandler).Methods("PUT")
+	adminRouter.HandleFunc("/decks/{id:[0-9]+}/transfer", TransferDeckHandler).Methods("POST")
+	adminRouter.HandleFunc("/formats", CreateFormatHandler).Methods("POST")
+	adminRouter.HandleFunc("/formats/reload", ReloadFormatsHandler).Methods("POST")
+	adminRouter.HandleFunc("/cards/reload", ReloadCardsHandler).Methods("POST")
+
+	// Format endpoints
+	apiRouter.HandleFunc("/formats", ListFormatsHandler).Methods("GET")
+	apiRouter.HandleFunc("/formats/{id}", GetFormatHandler).Methods("GET")
+	apiRouter.HandleFunc("/formats/{id}/decks", FormatDecksHandler).Methods("GET")
+
+	apiRouter.Handle("/cards", authMiddleware.OptionalAuth(http.HandlerFunc(SearchCardsHandler))).Methods("GET")
+	apiRouter.Handle("/cards/{id}", authMiddleware.OptionalAuth(http.HandlerFunc(GetCardHandler))).Methods("GET")
+	apiRouter.Handle("/overlays", authMiddleware.OptionalAuth(http.HandlerFunc(ListOverlaysHandler))).Methods("GET")
+	apiRouter.Handle("/overlays", authMiddleware.RequireAuth(http.HandlerFunc(CreateOverlayHandler))).Methods("POST")
+	apiRouter.Handle("/overlays/{id:[0-9]+}", authMiddleware.OptionalAuth(http.HandlerFunc(GetOverlayHandler))).Methods("GET")
+	apiRouter.Handle("/overlays/{id:[0-9]+}/versions", authMiddleware.OptionalAuth(http.HandlerFunc(ListOverlayVersionsHandler))).Methods("GET")
 	apiRouter.HandleFunc("/decks", ListDecksHandler).Methods("GET")
-	apiRouter.HandleFunc("/decks", CreateDeckHandler).Methods("POST")
+	apiRouter.Handle("/decks", authMiddleware.RequireAuth(http.HandlerFunc(CreateDeckHandler))).Methods("POST")
+	apiRouter.Handle("/decks/validate", authMiddleware.OptionalAuth(http.HandlerFunc(ValidateDeckHandler))).Methods("POST")
+	apiRouter.Handle("/decks/import", authMiddleware.OptionalAuth(http.HandlerFunc(ImportDeckHandler))).Methods("POST")
+	apiRouter.HandleFunc("/decks/compare", CompareDecksHandler).Methods("GET")
 	apiRouter.HandleFunc("/decks/{id:[0-9]+}", GetDeckHandler).Methods("GET")
-	apiRouter.HandleFunc("/decks/{id:[0-9]+}", UpdateDeckHandler).Methods("PUT")
-	apiRouter.HandleFunc("/decks/{id:[0-9]+}", DeleteDeckHandler).Methods("DELETE")
+	apiRouter.Handle("/decks/{id:[0-9]+}", authMiddleware.RequireAuth(http.HandlerFunc(UpdateDeckHandler))).Methods("PUT")
+	apiRouter.Handle("/decks/{id:[0-9]+}", authMiddleware.RequireAuth(http.HandlerFunc(DeleteDeckHandler))).Methods("DELETE")
+	apiRouter.HandleFunc("/decks/{id:[0-9]+}/export", ExportDeckHandler).Methods("GET")
+	apiRouter.HandleFunc("/decks/{id:[0-9]+}/versions", ListDeckVersionsHandler).Methods("GET")
+	apiRouter.HandleFunc("/decks/{id:[0-9]+}/versions/{version:[0-9]+}", GetDeckVersionHandler).Methods("GET")
+	apiRouter.HandleFunc("/decks/{id:[0-9]+}/diff", DeckDiffHandler).Methods("GET")
+	apiRouter.HandleFunc("/decks/{id:[0-9]+}/stats", DeckStatsHandler).Methods("GET")
+	apiRouter.HandleFunc("/decks/{id:[0-9]+}/probability", DeckProbabilityHandler).Methods("POST")
 	// Keep legacy name-based endpoints for backward compatibility during migration
 	apiRouter.HandleFunc("/decks/name/{deckname}", GetDeckByNameHandler).Methods("GET")
 
 	// Game management endpoints
 	apiRouter.HandleFunc("/games", ListGamesHandler).Methods("GET")
-	apiRouter.HandleFunc("/games", CreateGameHandler).Methods("POST")
-	apiRouter.HandleFunc("/games/{id}", GetGameHandler).Methods("GET")
-	apiRouter.HandleFunc("/games/{id}", DeleteGameHandler).Methods("DELETE")
-	apiRouter.HandleFunc("/games/{id}/actions", GameAvailableActionsHandler).Methods("GET")
-	apiRouter.HandleFunc("/games/{id}/execute", ExecuteActionHandler).Methods("POST")
-	apiRouter.HandleFunc("/games/{id}/steps", GameStepsHandler).Methods("GET")
-	apiRouter.HandleFunc("/games/{id}/history", GameHistoryHandler).Methods("GET")
-	apiRouter.HandleFunc("/games/{id}/navigation", StepsNavigationHandler).Methods("GET")
-	apiRouter.HandleFunc("/games/{id}/state", GameStateHandler).Methods("GET")
-	apiRouter.HandleFunc("/games/{id}/battlefield", GameBattlefieldHandler).Methods("GET")
+	apiRouter.Handle("/games", authMiddleware.OptionalAuth(http.HandlerFunc(CreateGameHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}", authMiddleware.OptionalAuth(http.HandlerFunc(GetGameHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}", authMiddleware.RequireAuth(http.HandlerFunc(DeleteGameHandler))).Methods("DELETE")
+	apiRouter.Handle("/games/{id}/actions", authMiddleware.OptionalAuth(http.HandlerFunc(GameAvailableActionsHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/execute", authMiddleware.OptionalAuth(http.HandlerFunc(ExecuteActionHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/explore", authMiddleware.OptionalAuth(http.HandlerFunc(ExploreTurnHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/best", authMiddleware.OptionalAuth(http.HandlerFunc(BestPlayHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/fork", authMiddleware.OptionalAuth(http.HandlerFunc(ForkGameHandler))).Methods("POST")
+	apiRouter.HandleFunc("/games/{id}/tree", GameTreeHandler).Methods("GET")
+	apiRouter.Handle("/games/{id}/undo", authMiddleware.OptionalAuth(http.HandlerFunc(UndoHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/takebacks", authMiddleware.OptionalAuth(http.HandlerFunc(ListTakebacksHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/takebacks/{takeback:[0-9]+}/approve", authMiddleware.RequireAuth(http.HandlerFunc(ApproveTakebackHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/takebacks/{takeback:[0-9]+}/reject", authMiddleware.RequireAuth(http.HandlerFunc(RejectTakebackHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/spectators", authMiddleware.RequireAuth(http.HandlerFunc(UpdateSpectatorSettingsHandler))).Methods("PUT")
+	apiRouter.Handle("/games/{id}/seats", authMiddleware.OptionalAuth(http.HandlerFunc(GameSeatsHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/seats/{seat}/claim", authMiddleware.RequireAuth(http.HandlerFunc(ClaimSeatHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/seats/{seat}/leave", authMiddleware.RequireAuth(http.HandlerFunc(LeaveSeatHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/seats/{seat}/invite", authMiddleware.RequireAuth(http.HandlerFunc(InviteToSeatHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/seats/{seat}/bot", authMiddleware.RequireAuth(http.HandlerFunc(AssignBotHandler))).Methods("POST")
+	apiRouter.Handle("/games/{id}/events", authMiddleware.OptionalAuth(http.HandlerFunc(GameEventsHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/steps", authMiddleware.OptionalAuth(http.HandlerFunc(GameStepsHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/history", authMiddleware.OptionalAuth(http.HandlerFunc(GameHistoryHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/navigation", authMiddleware.OptionalAuth(http.HandlerFunc(StepsNavigationHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/state", authMiddleware.OptionalAuth(http.HandlerFunc(GameStateHandler))).Methods("GET")
+	apiRouter.Handle("/games/{id}/battlefield", authMiddleware.OptionalAuth(http.HandlerFunc(GameBattlefieldHandler))).Methods("GET")
 	apiRouter.HandleFunc("/cache/stats", CacheStatsHandler).Methods("GET")
 
+	// The frontend reads the same canonical card file as the server
+	r.HandleFunc("/cards.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, services.CardsPath())
+	}).Methods("GET")
+
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/" && r.URL.RawQuery == "" {
 			http.Redirect(w, r, "/nav.html", http.StatusFound)
@@ -75,3 +158,45 @@ func Run() error {
 
 	return http.ListenAndServe(":"+port, r)
 }
+
+// openCardDB loads card data from the source selected by CARDS_SOURCE: the
+// canonical card file ("file", the default) or the cards table ("database").
+// Missing card data is an error rather than an empty card pool.
+func openCardDB() (services.ReloadableCardDatabase, error) {
+	switch source := os.Getenv("CARDS_SOURCE"); source {
+	case "", "file":
+		cardDB := services.NewInMemoryCardDB()
+		if err := cardDB.LoadFromFile(services.CardsPath()); err != nil {
+			return nil, err
+		}
+		return cardDB, nil
+	case "database":
+		cardDB := services.NewPostgresCardDB(database.GetDB())
+		if err := cardDB.Reload(); err != nil {
+			return nil, err
+		}
+		if cardDB.Count() == 0 {
+			return nil, fmt.Errorf("cards table is empty; import cards with cmd/cards -db")
+		}
+		return cardDB, nil
+	default:
+		return nil, fmt.Errorf("unknown CARDS_SOURCE %q", source)
+	}
+}
+
+// listenForCardChanges reloads the cards table whenever it changes and drops
+// everything cached from the old card data
+func listenForCardChanges(cardDB *services.PostgresCardDB) {
+	err := cardDB.Listen(context.Background(), database.URL(), func(err error) {
+		if err != nil {
+			log.Printf("Failed to reload cards: %v", err)
+			return
+		}
+		lensProcessor.ClearCache()
+		overlay.ClearCache()
+		log.Printf("Reloaded %d cards", cardDB.Count())
+	})
+	if err != nil {
+		log.Printf("Card change listener stopped: %v", err)
+	}
+}
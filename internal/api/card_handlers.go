@@ -0,0 +1,154 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"quards/internal/lens/services"
+	"quards/internal/overlay"
+
+	"github.com/gorilla/mux"
+)
+
+// SearchCardsHandler searches the card database.
+//
+// Query parameters: q (name and body text), color, type, set, rarity,
+// classification and keyword (repeatable or comma-separated), minCost,
+// maxCost, inkable, sort (name, cost, set, id; prefix "-" for descending),
+// offset, limit, overlay (card overlay ID to apply) and lang. Names and text
+// are localized for ?lang= or Accept-Language.
+func SearchCardsHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	query := services.CardQuery{
+		Text:            params.Get("q"),
+		Colors:          listParam(params["color"]),
+		Types:           listParam(params["type"]),
+		Sets:            listParam(params["set"]),
+		Rarities:        listParam(params["rarity"]),
+		Classifications: listParam(params["classification"]),
+		Keywords:        listParam(params["keyword"]),
+		Sort:            services.SortByName,
+	}
+
+	var err error
+	if query.MinCost, err = optionalIntParam(params.Get("minCost")); err != nil {
+		writeError(w, "invalid minCost", http.StatusBadRequest)
+		return
+	}
+	if query.MaxCost, err = optionalIntParam(params.Get("maxCost")); err != nil {
+		writeError(w, "invalid maxCost", http.StatusBadRequest)
+		return
+	}
+	
+	if inkable := params.Get("inkable"); inkable != "" {
+		value, err := strconv.ParseBool(inkable)
+		if err != nil {
+			writeError(w, "invalid inkable", http.StatusBadRequest)
+			return
+		}
+		query.Inkable = &value
+	}
+	
+	if sortParam := params.Get("sort"); sortParam != "" {
+		query.Descending = strings.HasPrefix(sortParam, "-")
+		query.Sort = strings.TrimPrefix(sortParam, "-")
+		switch query.Sort {
+		case services.SortByName, services.SortByCost, services.SortBySet, services.SortByID:
+		default:
+			writeError(w, "invalid sort", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	if offset := params.Get("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			writeError(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := params.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+			writeError(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	cardDB, err := requestCardDB(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	language := requestLanguage(w, r)
+	result := cardDB.Search(query)
+	for i, card := range result.Cards {
+		result.Cards[i] = card.Localized(language)
+	}
+	
+	writeResponse(w, result)
+}
+
+// GetCardHandler returns a single card by its unique ID
+func GetCardHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	cardDB, err := requestCardDB(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	card, exists := cardDB.GetCard(vars["id"])
+	if !exists {
+		writeError(w, "card not found", http.StatusNotFound)
+		return
+	}
+	
+	writeResponse(w, card.Localized(requestLanguage(w, r)))
+}
+
+// listParam flattens repeated and comma-separated query values
+func listParam(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// optionalIntParam parses an integer query value, returning nil when it is absent
+func optionalIntParam(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// ReloadCardsHandler re-reads card data from its source without a restart (admin only)
+func ReloadCardsHandler(w http.ResponseWriter, r *http.Request) {
+	cardDB, ok := lensProcessor.Services().CardDB.(services.ReloadableCardDatabase)
+	if !ok {
+		writeError(w, "card database does not support reloading", http.StatusNotImplemented)
+		return
+	}
+	
+	if err := cardDB.Reload(); err != nil {
+		writeError(w, fmt.Sprintf("failed to reload cards: %v", err), http.StatusInternalServerError)
+		return
+	}
+	lensProcessor.ClearCache()
+	overlay.ClearCache()
+	
+	writeResponse(w, map[string]int{"cards": cardDB.Count()})
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	
 	"github.com/gorilla/mux"
+	"quards/internal/auth"
 	"quards/internal/deck"
 )
 
@@ -62,7 +64,9 @@ func GetDeckByNameHandler(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, deckData)
 }
 
-// CreateDeckHandler creates a new deck
+// CreateDeckHandler creates a new deck owned by the requesting user, or
+// saves over a deck of the same name they own. Cards from the overlay given
+// as ?overlay= may be used.
 func CreateDeckHandler(w http.ResponseWriter, r *http.Request) {
 	var deckData deck.Deck
 	decoder := json.NewDecoder(r.Body)
@@ -71,22 +75,34 @@ func CreateDeckHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	if err := deck.SaveDeck(&deckData); err != nil {
-		writeError(w, fmt.Sprintf("failed to save deck: %v", err), http.StatusBadRequest)
+	if !authorizeDeckSave(w, r, &deckData) {
+		return
+	}
+	
+	cardDB, err := deckCardDB(r, deckData.Format)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	if err := deck.SaveDeck(&deckData, cardDB); err != nil {
+		writeDeckSaveError(w, err)
 		return
 	}
 	
 	writeResponse(w, map[string]string{"message": "deck created successfully"})
 }
 
-// UpdateDeckHandler updates an existing deck
+// UpdateDeckHandler updates an existing deck by ID. Changing the name renames
+// the deck. Only the deck's owner and admins may update it. Cards from the
+// overlay given as ?overlay= may be used.
 func UpdateDeckHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	deckName := vars["deckname"]
+	idStr := vars["id"]
 	
-	// Security: basic path validation
-	if filepath.Base(deckName) != deckName {
-		writeError(w, "invalid deck name", http.StatusBadRequest)
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
 		return
 	}
 	
@@ -97,18 +113,41 @@ func UpdateDeckHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Ensure the deck name matches the URL
-	deckData.Name = deckName
+	if deckData.Name == "" {
+		writeError(w, "deck name is required", http.StatusBadRequest)
+		return
+	}
+	
+	existing, err := deck.LoadDeckByID(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	if !mayEditDeck(auth.GetUserFromContext(r), existing) {
+		writeError(w, errNotDeckOwner.Error(), http.StatusForbidden)
+		return
+	}
+	
+	cardDB, err := deckCardDB(r, deckData.Format)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	
-	if err := deck.SaveDeck(&deckData); err != nil {
-		writeError(w, fmt.Sprintf("failed to save deck: %v", err), http.StatusBadRequest)
+	if err := deck.UpdateDeck(id, &deckData, cardDB); err != nil {
+		writeDeckSaveError(w, err)
 		return
 	}
 	
-	writeResponse(w, map[string]string{"message": "deck updated successfully"})
+	writeResponse(w, map[string]interface{}{
+		"message": "deck updated successfully",
+		"id":      deckData.ID,
+		"version": deckData.Version,
+	})
 }
 
-// DeleteDeckHandler deletes a deck
+// DeleteDeckHandler deletes a deck. Only the deck's owner and admins may
+// delete it. Decks used by games are soft-deleted.
 func DeleteDeckHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
@@ -119,10 +158,357 @@ func DeleteDeckHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	if err := deck.DeleteDeckByID(id); err != nil {
+	existing, err := deck.LoadDeckByID(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	if !mayEditDeck(auth.GetUserFromContext(r), existing) {
+		writeError(w, errNotDeckOwner.Error(), http.StatusForbidden)
+		return
+	}
+	
+	softDeleted, err := deck.DeleteDeckByID(id)
+	if err != nil {
 		writeError(w, fmt.Sprintf("failed to delete deck: %v", err), http.StatusInternalServerError)
 		return
 	}
 	
-	writeResponse(w, map[string]string{"message": "deck deleted successfully"})
-}
\ No newline at end of file
+	message := "deck deleted successfully"
+	if softDeleted {
+		message = "deck archived because games still reference it"
+	}
+	writeResponse(w, map[string]interface{}{"message": message, "archived": softDeleted})
+}
+
+// DeckValidationResult reports whether a deck is legal and why not
+type DeckValidationResult struct {
+	Legal      bool                   `json:"legal"`
+	CardCount  int                    `json:"cardCount"`
+	Violations deck.ValidationErrors  `json:"violations"`
+	Cards      map[string]int         `json:"cards,omitempty"`     // Resolved card IDs, for import previews
+	Sideboard  []deck.ImportLineError `json:"sideboard,omitempty"` // Import lines left out of the deck
+}
+
+// ValidateDeckHandler checks a deck list against deckbuilding rules without saving it
+func ValidateDeckHandler(w http.ResponseWriter, r *http.Request) {
+	var deckData deck.Deck
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&deckData); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	
+	cardDB, err := deckCardDB(r, deckData.Format)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	violations := deckData.Violations(cardDB)
+	writeResponse(w, DeckValidationResult{
+		Legal:      len(violations) == 0,
+		CardCount:  deckData.GetCardCount(),
+		Violations: violations,
+	})
+}
+
+// writeDeckSaveError reports a failed save, including rule violations when the deck is illegal
+func writeDeckSaveError(w http.ResponseWriter, err error) {
+	var violations deck.ValidationErrors
+	if errors.As(err, &violations) {
+		writeErrorWithData(w, err.Error(), violations, http.StatusBadRequest)
+		return
+	}
+	writeError(w, fmt.Sprintf("failed to save deck: %v", err), http.StatusBadRequest)
+}
+
+var errNotDeckOwner = errors.New("only the deck's owner or an admin may change it")
+
+// mayEditDeck reports whether the user owns the deck or is an admin
+func mayEditDeck(user *auth.User, deckData *deck.Deck) bool {
+	return user != nil && (deckData.UserID == user.ID || user.HasRole(auth.RoleAdmin))
+}
+
+// authorizeDeckSave makes the requesting user the owner of a deck saved by
+// name, writing an error and returning false if they may not save it
+func authorizeDeckSave(w http.ResponseWriter, r *http.Request, deckData *deck.Deck) bool {
+	user := auth.GetUserFromContext(r)
+	if user == nil {
+		writeError(w, "authentication required", http.StatusUnauthorized)
+		return false
+	}
+	
+	// Saving an existing name overwrites that deck
+	if existing, err := deck.LoadDeck(deckData.Name); err == nil && !mayEditDeck(user, existing) {
+		writeError(w, errNotDeckOwner.Error(), http.StatusForbidden)
+		return false
+	}
+	
+	deckData.UserID = user.ID
+	return true
+}
+
+// ImportDeckRequest represents a request to create a deck from a text deck list
+type ImportDeckRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Format      string `json:"format"`
+	Text        string `json:"text"` // One "quantity card name" per line
+}
+
+// ImportDeckHandler creates a deck from a community-style text deck list.
+// Sideboard lines are returned but never added to the deck. With
+// ?preview=true the list is resolved and validated but not saved; saving
+// requires a signed-in user, as for CreateDeckHandler.
+func ImportDeckHandler(w http.ResponseWriter, r *http.Request) {
+	var req ImportDeckRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	
+	cardDB, err := deckCardDB(r, req.Format)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	// Unknown formats are reported by validation below
+	target, _ := (&deck.Deck{Format: req.Format}).TargetFormat()
+	result := deck.ParseDeckText(req.Text, cardDB, target)
+	if len(result.Errors) > 0 {
+		writeErrorWithData(w, fmt.Sprintf("%d line(s) could not be imported", len(result.Errors)), result, http.StatusBadRequest)
+		return
+	}
+	
+	deckData := deck.Deck{
+		Name:        req.Name,
+		Description: req.Description,
+		Format:      req.Format,
+		Cards:       result.Cards,
+	}
+	
+	if r.URL.Query().Get("preview") == "true" {
+		violations := deckData.Violations(cardDB)
+		writeResponse(w, DeckValidationResult{
+			Legal:      len(violations) == 0,
+			CardCount:  deckData.GetCardCount(),
+			Violations: violations,
+			Cards:      deckData.Cards,
+			Sideboard:  result.Sideboard,
+		})
+		return
+	}
+	
+	if req.Name == "" {
+		writeError(w, "deck name is required", http.StatusBadRequest)
+		return
+	}
+	
+	if !authorizeDeckSave(w, r, &deckData) {
+		return
+	}
+	
+	if err := deck.SaveDeck(&deckData, cardDB); err != nil {
+		writeDeckSaveError(w, err)
+		return
+	}
+	
+	writeResponse(w, map[string]interface{}{
+		"message":   "deck imported successfully",
+		"cards":     deckData.Cards,
+		"sideboard": result.Sideboard,
+	})
+}
+
+// ExportDeckHandler returns a deck as a community-style plain-text deck list
+func ExportDeckHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+	
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	
+	deckData, err := deck.LoadDeckByID(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", deckData.Name+".txt"))
+	w.Write([]byte(deck.ExportDeckText(deckData, lensProcessor.Services().CardDB)))
+}
+
+// ListDeckVersionsHandler returns every saved version of a deck
+func ListDeckVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	
+	versions, err := deck.ListVersions(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to list deck versions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	writeResponse(w, versions)
+}
+
+// GetDeckVersionHandler returns a specific version of a deck
+func GetDeckVersionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		writeError(w, "invalid version", http.StatusBadRequest)
+		return
+	}
+	
+	deckVersion, err := deck.LoadVersion(id, version)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck version: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	writeResponse(w, deckVersion)
+}
+
+// DeckDiffHandler compares two versions of a deck. Without parameters it
+// compares the latest version against the one before it; for a deck saved
+// only once that is version 0, the empty deck.
+func DeckDiffHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	
+	deckData, err := deck.LoadDeckByID(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	to := deckData.Version
+	if toParam := r.URL.Query().Get("to"); toParam != "" {
+		if to, err = strconv.Atoi(toParam); err != nil {
+			writeError(w, "invalid 'to' version", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	from := to - 1
+	if fromParam := r.URL.Query().Get("from"); fromParam != "" {
+		if from, err = strconv.Atoi(fromParam); err != nil {
+			writeError(w, "invalid 'from' version", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	if from < 0 || to < 0 {
+		writeError(w, "versions must not be negative", http.StatusBadRequest)
+		return
+	}
+	
+	diff, err := deck.DiffVersions(id, from, to)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to diff deck versions: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	writeResponse(w, diff)
+}
+
+// DeckStatsHandler returns cost curve, ink, color, type and keyword statistics for a deck
+func DeckStatsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	
+	deckData, err := deck.LoadDeckByID(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	writeResponse(w, deck.ComputeStats(deckData.Cards, lensProcessor.Services().CardDB))
+}
+
+// CompareDecksHandler compares two decks given as ?a=<id>&b=<id>
+func CompareDecksHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	
+	idA, err := strconv.Atoi(query.Get("a"))
+	if err != nil {
+		writeError(w, "invalid deck ID for 'a'", http.StatusBadRequest)
+		return
+	}
+	
+	idB, err := strconv.Atoi(query.Get("b"))
+	if err != nil {
+		writeError(w, "invalid deck ID for 'b'", http.StatusBadRequest)
+		return
+	}
+	
+	deckA, err := deck.LoadDeckByID(idA)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	deckB, err := deck.LoadDeckByID(idB)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	writeResponse(w, deck.CompareDecks(deckA, deckB, lensProcessor.Services().CardDB))
+}
+
+// DeckProbabilityHandler calculates draw probabilities for a deck
+func DeckProbabilityHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	
+	var query deck.ProbabilityQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	
+	deckData, err := deck.LoadDeckByID(id)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load deck: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	result, err := deck.DrawProbability(deckData.Cards, lensProcessor.Services().CardDB, query)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	writeResponse(w, result)
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"quards/internal/events"
+	"quards/internal/game"
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+
+	"github.com/gorilla/mux"
+)
+
+// keepAliveInterval keeps idle event streams open through proxies
+const keepAliveInterval = 25 * time.Second
+
+// releaseInterval is how often time-delayed spectators are checked for newly due steps
+const releaseInterval = 5 * time.Second
+
+// GameEventsHandler streams a game's updates as server-sent events.
+//
+// The stream starts with a "snapshot" event holding the current state and any
+// entries after ?since=<step> (or the Last-Event-ID header), followed by an
+// "append" or "reset" event for every change. Event IDs are step counts, so a
+// reconnecting EventSource resumes where it left off. Entries and state are
+// redacted for the requesting user like every other view of the game, and
+// spectators receive steps only once the game's spectator delay releases them.
+func GameEventsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	
+	since := -1
+	sinceParam := r.URL.Query().Get("since")
+	if sinceParam == "" {
+		sinceParam = r.Header.Get("Last-Event-ID")
+	}
+	if sinceParam != "" {
+		var err error
+		if since, err = strconv.Atoi(sinceParam); err != nil || since < 0 {
+			writeError(w, "invalid since step", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+	
+	// Subscribe before taking the snapshot so no update falls in between
+	updates, unsubscribe := events.Default.Subscribe(gameID)
+	defer unsubscribe()
+	
+	gameData, err := game.LoadGame(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load game: %v", err), http.StatusNotFound)
+		return
+	}
+	view, err := gameView(r, gameData)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	snapshot, err := gameSnapshot(gameData, since, view)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	
+	if err := writeEvent(w, "snapshot", snapshot.Step, snapshot); err != nil {
+		return
+	}
+	flusher.Flush()
+	
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	
+	// Time-delayed spectators are sent steps as they come due
+	var release <-chan time.Time
+	if view.Delayed && gameData.Spectators.DelayMinutes > 0 {
+		releaseTicker := time.NewTicker(releaseInterval)
+		defer releaseTicker.Stop()
+		release = releaseTicker.C
+	}
+	
+	lastStep := snapshot.Step
+	for {
+		var event *events.GameEvent
+		select {
+		case <-r.Context().Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			if view.Delayed {
+				if event, err = delayedEvent(gameID, view, lastStep, update.Type == events.TypeReset); err != nil {
+					return
+				}
+				break
+			}
+			// Skip appends already covered by the snapshot
+			if update.Type == events.TypeAppend && update.Step <= lastStep {
+				continue
+			}
+			redacted := redactEvent(update, view.Viewer)
+			event = &redacted
+		case <-release:
+			if event, err = delayedEvent(gameID, view, lastStep, false); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+		
+		if event == nil {
+			continue
+		}
+		lastStep = event.Step
+		if err := writeEvent(w, event.Type, event.Step, event); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// gameSnapshot builds the initial event for a subscriber
+func gameSnapshot(gameData *game.Game, since int, view *game.View) (*events.GameEvent, error) {
+	entries, err := parser.ParseLogContent(gameData.LogContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse game log: %w", err)
+	}
+	steps, err := view.VisibleSteps(gameData, entries)
+	if err != nil {
+		return nil, err
+	}
+	return viewEvent(gameData, entries[:steps], view.Viewer, "snapshot", since)
+}
+
+// delayedEvent returns the event that brings a delayed subscriber from
+// lastStep up to the steps released to them, or nil if none were. A reset of
+// the game always resends the state.
+func delayedEvent(gameID int, view *game.View, lastStep int, reset bool) (*events.GameEvent, error) {
+	gameData, err := game.LoadGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parser.ParseLogContent(gameData.LogContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse game log: %w", err)
+	}
+	steps, err := view.VisibleSteps(gameData, entries)
+	if err != nil {
+		return nil, err
+	}
+	
+	switch {
+	case !reset && steps == lastStep:
+		return nil, nil
+	case !reset && steps > lastStep:
+		return viewEvent(gameData, entries[:steps], view.Viewer, events.TypeAppend, lastStep)
+	default:
+		return viewEvent(gameData, entries[:steps], view.Viewer, events.TypeReset, -1)
+	}
+}
+
+// viewEvent builds an event holding the state after entries, as the viewer
+// sees it, and the entries from fromStep on if fromStep is not negative
+func viewEvent(gameData *game.Game, entries []parser.LogEntry, viewer *services.Viewer, eventType string, fromStep int) (*events.GameEvent, error) {
+	processor, err := game.LensProcessor(gameData)
+	if err != nil {
+		return nil, err
+	}
+	state, err := processor.WithViewer(viewer).Lens("composite", entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute game state: %w", err)
+	}
+	
+	event := &events.GameEvent{
+		Type:    eventType,
+		GameID:  gameData.ID,
+		Step:    len(entries),
+		Entries: []events.Entry{},
+		State:   state,
+	}
+	if fromStep >= 0 && fromStep < len(entries) {
+		event.Entries = game.EventEntries(core.RedactEntries(entries[fromStep:], viewer))
+	}
+	return event, nil
+}
+
+// redactEvent hides what the viewer may not see in a published event, which
+// is shared by every subscriber and so is copied rather than modified
+func redactEvent(event events.GameEvent, viewer *services.Viewer) events.GameEvent {
+	if viewer == nil {
+		return event
+	}
+	
+	entries := make([]events.Entry, len(event.Entries))
+	for i, entry := range event.Entries {
+		redacted := core.RedactEntry(parser.LogEntry{
+			Event:      parser.LogEventType(entry.Event),
+			Parameters: entry.Parameters,
+			Step:       entry.Step,
+		}, viewer)
+		entry.Parameters = redacted.Parameters
+		entries[i] = entry
+	}
+	event.Entries = entries
+	event.State = core.RedactGameState(event.State, viewer)
+	return event
+}
+
+// writeEvent writes one server-sent event with a JSON payload
+func writeEvent(w http.ResponseWriter, eventType string, id int, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, eventType, payload)
+	return err
+}
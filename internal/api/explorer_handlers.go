@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"quards/internal/evaluator"
+	"quards/internal/explorer"
+	"quards/internal/game"
+	"quards/internal/parser"
+
+	"github.com/gorilla/mux"
+)
+
+// Upper bounds on explorer budgets requested through the API
+const (
+	maxExploreDepth = 30
+	maxExploreNodes = 20000
+)
+
+// defaultBestPlayLimit is how many lines best play suggestions return by default
+const defaultBestPlayLimit = 5
+
+// exploreBudget reads the ?depth= and ?nodes= budget, within the API's bounds
+func exploreBudget(r *http.Request) (explorer.Budget, error) {
+	budget := explorer.DefaultBudget
+	query := r.URL.Query()
+	if depth := query.Get("depth"); depth != "" {
+		value, err := strconv.Atoi(depth)
+		if err != nil || value < 1 || value > maxExploreDepth {
+			return budget, fmt.Errorf("depth must be between 1 and %d", maxExploreDepth)
+		}
+		budget.MaxDepth = value
+	}
+	if nodes := query.Get("nodes"); nodes != "" {
+		value, err := strconv.Atoi(nodes)
+		if err != nil || value < 1 || value > maxExploreNodes {
+			return budget, fmt.Errorf("nodes must be between 1 and %d", maxExploreNodes)
+		}
+		budget.MaxNodes = value
+	}
+	return budget, nil
+}
+
+// exploreGame explores the current turn of a game, or of its first ?step=N
+// entries, as the requesting user sees it. It writes the error response and
+// returns nil when the turn cannot be explored.
+func exploreGame(w http.ResponseWriter, r *http.Request) *explorer.Result {
+	vars := mux.Vars(r)
+	gameData, err := game.LoadGameByID(vars["id"])
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load game: %v", err), http.StatusNotFound)
+		return nil
+	}
+
+	entries, err := parser.ParseLogContent(gameData.LogContent)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to parse game log: %v", err), http.StatusBadRequest)
+		return nil
+	}
+
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return nil
+	}
+
+	if stepParam := r.URL.Query().Get("step"); stepParam != "" {
+		if stepNum, err := strconv.Atoi(stepParam); err == nil && stepNum > 0 && stepNum <= len(entries) {
+			entries = entries[:stepNum]
+		}
+	}
+
+	budget, err := exploreBudget(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	result, err := explorer.Explore(entries, processor.Services(), budget)
+	if errors.Is(err, explorer.ErrHiddenHand) {
+		writeError(w, err.Error(), http.StatusForbidden)
+		return nil
+	}
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to explore turn: %v", err), http.StatusInternalServerError)
+		return nil
+	}
+	return result
+}
+
+// ExploreTurnHandler lists every distinct position the current player can
+// end their turn in, with the line of actions reaching it
+func ExploreTurnHandler(w http.ResponseWriter, r *http.Request) {
+	if result := exploreGame(w, r); result != nil {
+		writeResponse(w, result)
+	}
+}
+
+// BestPlayHandler ranks the lines the current player can take this turn by
+// the default evaluator's score of the position each ends in, best first.
+// ?limit=N returns only the N best lines.
+func BestPlayHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultBestPlayLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		value, err := strconv.Atoi(limitParam)
+		if err != nil || value < 1 {
+			writeError(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = value
+	}
+
+	result := exploreGame(w, r)
+	if result == nil {
+		return
+	}
+
+	lines := evaluator.Rank(result, evaluator.NewHeuristic())
+	if len(lines) > limit {
+		lines = lines[:limit]
+	}
+	writeResponse(w, map[string]interface{}{
+		"player":    result.Player,
+		"turn":      result.Turn,
+		"explored":  result.Explored,
+		"truncated": result.Truncated,
+		"lines":     lines,
+	})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"quards/internal/deck"
+	"quards/internal/format"
+)
+
+// ListFormatsHandler returns all registered formats
+func ListFormatsHandler(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, format.List())
+}
+
+// GetFormatHandler returns a specific format by ID
+func GetFormatHandler(w http.ResponseWriter, r *http.Request) {
+	formatID := mux.Vars(r)["id"]
+
+	f, exists := format.Get(formatID)
+	if !exists {
+		writeError(w, fmt.Sprintf("format not found: %s", formatID), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, f)
+}
+
+// FormatDeckLegality reports whether a saved deck is legal in a format
+type FormatDeckLegality struct {
+	DeckID     int                   `json:"deckId"`
+	DeckName   string                `json:"deckName"`
+	Legal      bool                  `json:"legal"`
+	Violations deck.ValidationErrors `json:"violations"`
+}
+
+// FormatDecksHandler checks every saved deck against a format, e.g. to catch rotated cards
+func FormatDecksHandler(w http.ResponseWriter, r *http.Request) {
+	formatID := mux.Vars(r)["id"]
+
+	f, exists := format.Get(formatID)
+	if !exists {
+		writeError(w, fmt.Sprintf("format not found: %s", formatID), http.StatusNotFound)
+		return
+	}
+
+	decks, err := deck.ListDecks()
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to list decks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	cardDB := lensProcessor.Services().CardDB
+	results := make([]FormatDeckLegality, 0, len(decks))
+	for _, summary := range decks {
+		deckData, err := deck.LoadDeckByID(summary.ID)
+		if err != nil {
+			continue // Skip decks that can no longer be loaded
+		}
+
+		violations := deckData.ViolationsInFormat(cardDB, f)
+		results = append(results, FormatDeckLegality{
+			DeckID:     deckData.ID,
+			DeckName:   deckData.Name,
+			Legal:      len(violations) == 0,
+			Violations: violations,
+		})
+	}
+
+	writeResponse(w, results)
+}
+
+// CreateFormatHandler registers a format definition and saves it for future restarts
+func CreateFormatHandler(w http.ResponseWriter, r *http.Request) {
+	var f format.Format
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := format.Save(&f, format.DefaultDir); err != nil {
+		writeError(w, fmt.Sprintf("failed to save format: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, &f)
+}
+
+// ReloadFormatsHandler re-reads all format definition files from disk,
+// dropping formats whose files were removed. On error the formats in use are kept.
+func ReloadFormatsHandler(w http.ResponseWriter, r *http.Request) {
+	loaded, err := format.LoadDir(format.DefaultDir)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load formats: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeResponse(w, map[string]interface{}{
+		"loaded":  len(loaded),
+		"formats": format.List(),
+	})
+}
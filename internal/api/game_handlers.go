@@ -2,15 +2,20 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	
 	"github.com/gorilla/mux"
+	"quards/internal/auth"
+	"quards/internal/bot"
 	"quards/internal/game"
 )
 
-// CreateGameHandler creates a new game
+// CreateGameHandler creates a new game. A signed-in creator takes the
+// requested seat; the other seat is left for an invited or open player, or
+// handed to the requested bot.
 func CreateGameHandler(w http.ResponseWriter, r *http.Request) {
 	var req game.CreateGameRequest
 	decoder := json.NewDecoder(r.Body)
@@ -24,12 +29,72 @@ func CreateGameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	if req.Seat == 0 {
+		req.Seat = 1
+	}
+	if req.Seat != 1 && req.Seat != 2 {
+		writeError(w, "seat must be 1 or 2", http.StatusBadRequest)
+		return
+	}
+	if req.Bot != "" && !bot.IsKind(req.Bot) {
+		writeError(w, fmt.Sprintf("unknown bot %q, expected one of %v", req.Bot, bot.Kinds), http.StatusBadRequest)
+		return
+	}
+	
+	// The seed fixes every library's order, so players may not choose it for
+	// games with hidden information
+	userID := auth.GetUserIDFromContext(r)
+	seated := userID > 0 || req.OpenSeat || req.Bot != ""
+	if seated && (req.Seed != nil || req.LogContent != "") {
+		writeError(w, "seed and logContent can only be given for games nobody is seated in", http.StatusBadRequest)
+		return
+	}
+	
+	// Other users' personal overlays stay private
+	if req.CardOverlay != "" {
+		if _, err := visibleOverlay(r, req.CardOverlay); errors.Is(err, errOverlayHidden) {
+			writeError(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	
+	req.CreatorID = userID
 	createdGame, err := game.CreateGame(&req)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to create game: %v", err), http.StatusBadRequest)
 		return
 	}
 	
+	seats, err := game.CreateSeats(createdGame.ID, userID, req.Seat, req.OpenSeat)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to create seats: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if req.Bot != "" {
+		if err := game.SeatBot(createdGame.ID, 3-req.Seat, req.Bot); err != nil {
+			writeError(w, fmt.Sprintf("failed to seat bot: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if seats, err = game.LoadSeats(createdGame.ID); err != nil {
+			writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+			return
+		}
+		playBots(createdGame.ID)
+	}
+	
+	// The creator is seated now, so the opponent's opening hand is hidden
+	view, err := gameView(r, createdGame)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	createdGame, err = game.Redacted(createdGame, view)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	createdGame.Seats = game.HideInviteTokens(seats, userID)
+	
 	writeResponse(w, createdGame)
 }
 
@@ -61,11 +126,32 @@ func GetGameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	userID := auth.GetUserIDFromContext(r)
+	seats, err := game.LoadSeats(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	// The raw log holds both hands, so it is redacted like lens output
+	view, err := gameView(r, gameData)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	gameData, err = game.Redacted(gameData, view)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	gameData.Seats = game.HideInviteTokens(seats, userID)
+	
 	writeResponse(w, gameData)
 }
 
 
-// DeleteGameHandler deletes a game
+// DeleteGameHandler deletes a game. Once a seat is taken, only seated
+// players and organizers may delete it.
 func DeleteGameHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID, err := strconv.Atoi(vars["id"])
@@ -74,6 +160,19 @@ func DeleteGameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	user := auth.GetUserFromContext(r)
+	if !user.HasRole(auth.RoleOrganizer) {
+		seats, err := game.LoadSeats(gameID)
+		if err != nil {
+			writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !game.MayManage(seats, user.ID) {
+			writeError(w, game.ErrNotSeated.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	
 	if err := game.DeleteGame(gameID); err != nil {
 		writeError(w, fmt.Sprintf("failed to delete game: %v", err), http.StatusInternalServerError)
 		return
@@ -88,7 +187,8 @@ type ExecuteActionRequest struct {
 	Parameters map[string]interface{} `json:"parameters"`
 }
 
-// ExecuteActionHandler executes an action in a game and appends it to the log
+// ExecuteActionHandler executes an action in a game and appends it to the log.
+// Once a seat is taken, only the user seated as the acting player may execute.
 func ExecuteActionHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
@@ -106,11 +206,118 @@ func ExecuteActionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Execute the action by appending to the game log
-	err := game.AppendActionToGameByID(gameID, req.Type, req.Parameters)
+	err := game.AppendActionAsUser(gameID, auth.GetUserIDFromContext(r), req.Type, req.Parameters)
+	if errors.Is(err, game.ErrNotSeated) || errors.Is(err, game.ErrNotYourTurn) {
+		writeError(w, err.Error(), http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to execute action: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if id, err := strconv.Atoi(gameID); err == nil {
+		playBots(id)
+	}
 	
 	writeResponse(w, map[string]string{"message": "action executed successfully"})
-}
\ No newline at end of file
+}
+
+// UpdateSpectatorSettingsHandler changes a game's spectator delay and caster
+// view. Only seated players and organizers may change them.
+func UpdateSpectatorSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+	
+	var settings game.SpectatorSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	
+	user := auth.GetUserFromContext(r)
+	if !user.HasRole(auth.RoleOrganizer) {
+		seats, err := game.LoadSeats(gameID)
+		if err != nil {
+			writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if game.SeatOf(seats, user.ID) == 0 {
+			writeError(w, game.ErrNotSeated.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	
+	if err := game.UpdateSpectatorSettings(gameID, settings); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	writeResponse(w, settings)
+}
+
+// ForkGameHandler creates a new game from the first ?step=N log entries of a
+// game. Only games whose whole log the user may see can be forked, so a fork
+// cannot be used to look at hidden cards.
+func ForkGameHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+	
+	step, err := strconv.Atoi(r.URL.Query().Get("step"))
+	if err != nil {
+		writeError(w, "step is required", http.StatusBadRequest)
+		return
+	}
+	
+	gameData, err := game.LoadGame(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load game: %v", err), http.StatusNotFound)
+		return
+	}
+	view, err := gameView(r, gameData)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	if view.Viewer != nil || view.Delayed {
+		writeError(w, "games with hidden information can only be forked once completed", http.StatusForbidden)
+		return
+	}
+	
+	fork, err := game.ForkGame(gameID, step)
+	if errors.Is(err, game.ErrInvalidForkStep) {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to fork game: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	writeResponse(w, fork)
+}
+
+// GameTreeHandler returns a game with all games forked from it
+func GameTreeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+	
+	tree, err := game.GameTree(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load game tree: %v", err), http.StatusNotFound)
+		return
+	}
+	
+	writeResponse(w, tree)
+}
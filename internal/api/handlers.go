@@ -2,23 +2,22 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	
 	"github.com/gorilla/mux"
+	"quards/internal/auth"
 	"quards/internal/game"
+	"quards/internal/i18n"
 	"quards/internal/lens"
 	"quards/internal/parser"
 )
 
-// Global lens processor for API handlers
+// Global lens processor for API handlers, created by Run once card data is loaded
 var lensProcessor *lens.Processor
 
-func init() {
-	lensProcessor = lens.New()
-}
-
 type Response struct {
 	Data interface{} `json:"data"`
 	Error string     `json:"error,omitempty"`
@@ -47,6 +46,12 @@ func GameAvailableActionsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	
 	// Check if step parameter is provided for historical context
 	stepParam := r.URL.Query().Get("step")
 	if stepParam != "" {
@@ -56,7 +61,7 @@ func GameAvailableActionsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
-	actions, err := lensProcessor.Lens("availableActions", entries)
+	actions, err := processor.WithLanguage(requestLanguage(w, r)).Lens("availableActions", entries)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to compute available actions: %v", err), http.StatusInternalServerError)
 		return
@@ -64,35 +69,6 @@ func GameAvailableActionsHandler(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, actions)
 }
 
-func GameTruncateHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	gameID := vars["id"]
-	
-	var req struct {
-		LogContent string `json:"logContent"`
-	}
-	
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-	
-	// Load game from database to verify it exists
-	_, err := game.LoadGameByID(gameID)
-	if err != nil {
-		writeError(w, fmt.Sprintf("failed to load game: %v", err), http.StatusNotFound)
-		return
-	}
-	
-	// Update the game with the truncated log content
-	if err := game.TruncateGameByID(gameID, req.LogContent); err != nil {
-		writeError(w, fmt.Sprintf("failed to truncate game: %v", err), http.StatusInternalServerError)
-		return
-	}
-	
-	writeResponse(w, map[string]string{"status": "success"})
-}
-
 func GameStepsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
@@ -111,7 +87,13 @@ func GameStepsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	steps, err := lensProcessor.Lens("gameSteps", entries)
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	
+	steps, err := processor.Lens("gameSteps", entries)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to compute game steps: %v", err), http.StatusInternalServerError)
 		return
@@ -137,6 +119,13 @@ func GameStateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Use composite lens to get all game state
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	
 	// Check if step parameter is provided for historical context
 	stepParam := r.URL.Query().Get("step")
 	if stepParam != "" {
@@ -146,8 +135,7 @@ func GameStateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
-	// Use composite lens to get all game state
-	gameState, err := lensProcessor.Lens("composite", entries)
+	gameState, err := processor.Lens("composite", entries)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to compute game state: %v", err), http.StatusInternalServerError)
 		return
@@ -173,6 +161,12 @@ func GameBattlefieldHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	
 	// Check if step parameter is provided for historical context
 	stepParam := r.URL.Query().Get("step")
 	if stepParam != "" {
@@ -182,7 +176,7 @@ func GameBattlefieldHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
-	battlefield, err := lensProcessor.Lens("battlefield", entries)
+	battlefield, err := processor.Lens("battlefield", entries)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to compute battlefield: %v", err), http.StatusInternalServerError)
 		return
@@ -208,7 +202,13 @@ func GameHistoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	history, err := lensProcessor.Lens("history", entries)
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	
+	history, err := processor.WithLanguage(requestLanguage(w, r)).Lens("history", entries)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to compute game history: %v", err), http.StatusInternalServerError)
 		return
@@ -234,7 +234,13 @@ func StepsNavigationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	steps, err := lensProcessor.Lens("stepsNavigation", entries)
+	processor, entries, err := viewerProcessor(r, gameData, entries)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	
+	steps, err := processor.WithLanguage(requestLanguage(w, r)).Lens("stepsNavigation", entries)
 	if err != nil {
 		writeError(w, fmt.Sprintf("failed to compute navigation steps: %v", err), http.StatusInternalServerError)
 		return
@@ -247,8 +253,65 @@ func CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
 	writeResponse(w, stats)
 }
 
+// errCasterRole is returned when a user without the organizer role asks for the caster view
+var errCasterRole = errors.New("the caster view requires the organizer role")
+
+// gameView returns how the requesting user sees the game. ?view=caster asks
+// for the caster view, which is limited to organizers.
+func gameView(r *http.Request, gameData *game.Game) (*game.View, error) {
+	caster := r.URL.Query().Get("view") == "caster"
+	if caster {
+		user := auth.GetUserFromContext(r)
+		if user == nil || !user.HasRole(auth.RoleOrganizer) {
+			return nil, errCasterRole
+		}
+	}
+	return game.ViewFor(gameData, auth.GetUserIDFromContext(r), caster)
+}
+
+// viewerProcessor returns the game's lens processor, hiding what the
+// requesting user may not see, and the log entries released to them
+func viewerProcessor(r *http.Request, gameData *game.Game, entries []parser.LogEntry) (*lens.Processor, []parser.LogEntry, error) {
+	view, err := gameView(r, gameData)
+	if err != nil {
+		return nil, nil, err
+	}
+	steps, err := view.VisibleSteps(gameData, entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	processor, err := game.LensProcessor(gameData)
+	if err != nil {
+		return nil, nil, err
+	}
+	return processor.WithViewer(view.Viewer), entries[:steps], nil
+}
+
+// viewErrorStatus maps errors from resolving a view to HTTP status codes
+func viewErrorStatus(err error) int {
+	if errors.Is(err, errCasterRole) || errors.Is(err, game.ErrNoCasterView) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// requestLanguage picks the response language from ?lang= or Accept-Language
+// and records it in the Content-Language header
+func requestLanguage(w http.ResponseWriter, r *http.Request) string {
+	language := i18n.Negotiate(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", language)
+	return language
+}
+
 func writeError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(Response{Error: message})
-}
\ No newline at end of file
+}
+
+// writeErrorWithData writes an error response that also carries structured details
+func writeErrorWithData(w http.ResponseWriter, message string, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(Response{Data: data, Error: message})
+}
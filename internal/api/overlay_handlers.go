@@ -0,0 +1,219 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"quards/internal/auth"
+	"quards/internal/format"
+	"quards/internal/lens/services"
+	"quards/internal/overlay"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateOverlayRequest saves a new version of a card overlay. Format overlays
+// (errata) require the organizer role; without a format the overlay is
+// personal to the requesting user.
+type CreateOverlayRequest struct {
+	Name        string                        `json:"name"`
+	Description string                        `json:"description"`
+	Format      string                        `json:"format,omitempty"`
+	Patches     map[string]services.CardPatch `json:"patches"`
+}
+
+// errOverlayHidden is returned for another user's personal overlay
+var errOverlayHidden = errors.New("overlay belongs to another user")
+
+// mayViewOverlay reports whether the requesting user may see an overlay.
+// Format overlays are public; personal overlays are visible to their owner
+// and organizers.
+func mayViewOverlay(r *http.Request, ownerID *int) bool {
+	if ownerID == nil {
+		return true
+	}
+	user := auth.GetUserFromContext(r)
+	return user != nil && (user.ID == *ownerID || user.HasRole(auth.RoleOrganizer))
+}
+
+// visibleOverlay resolves an overlay ID or name the requesting user may see
+func visibleOverlay(r *http.Request, identifier string) (*overlay.Overlay, error) {
+	cardOverlay, err := overlay.Resolve(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if !mayViewOverlay(r, cardOverlay.UserID) {
+		return nil, errOverlayHidden
+	}
+	return cardOverlay, nil
+}
+
+// overlayErrorStatus maps errors from resolving an overlay to HTTP status codes
+func overlayErrorStatus(err error) int {
+	if errors.Is(err, errOverlayHidden) {
+		return http.StatusForbidden
+	}
+	return http.StatusNotFound
+}
+
+// ListOverlaysHandler lists the latest version of each overlay, optionally
+// filtered by ?user= or ?format=. Personal overlays are only listed for their
+// owner and organizers.
+func ListOverlaysHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	
+	userID := 0
+	if userParam := query.Get("user"); userParam != "" {
+		var err error
+		if userID, err = strconv.Atoi(userParam); err != nil {
+			writeError(w, "invalid user ID", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	overlays, err := overlay.ListOverlays(userID, query.Get("format"))
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to list overlays: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	visible := []overlay.OverlayList{}
+	for _, cardOverlay := range overlays {
+		if mayViewOverlay(r, cardOverlay.UserID) {
+			visible = append(visible, cardOverlay)
+		}
+	}
+	
+	writeResponse(w, visible)
+}
+
+// GetOverlayHandler returns an overlay version with its patches
+func GetOverlayHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cardOverlay, err := visibleOverlay(r, vars["id"])
+	if err != nil {
+		writeError(w, err.Error(), overlayErrorStatus(err))
+		return
+	}
+	
+	writeResponse(w, cardOverlay)
+}
+
+// ListOverlayVersionsHandler lists every version of the overlay with the given ID's name
+func ListOverlayVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cardOverlay, err := visibleOverlay(r, vars["id"])
+	if err != nil {
+		writeError(w, err.Error(), overlayErrorStatus(err))
+		return
+	}
+	
+	versions, err := overlay.ListVersions(cardOverlay.Name)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to list overlay versions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	writeResponse(w, versions)
+}
+
+// CreateOverlayHandler saves a card overlay as the next version of its name
+func CreateOverlayHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateOverlayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	
+	user := auth.GetUserFromContext(r)
+	if user == nil {
+		writeError(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	
+	cardOverlay := &overlay.Overlay{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Format:      req.Format,
+		Patches:     req.Patches,
+	}
+	if cardOverlay.Format != "" {
+		if !user.HasRole(auth.RoleOrganizer) {
+			writeError(w, "format overlays require the organizer role", http.StatusForbidden)
+			return
+		}
+	} else {
+		cardOverlay.UserID = &user.ID
+	}
+	
+	// New versions must keep the overlay's scope and owner
+	if existing, err := overlay.LoadLatestOverlay(cardOverlay.Name); err == nil {
+		if existing.Format != cardOverlay.Format {
+			writeError(w, "an overlay with this name already exists with a different scope", http.StatusConflict)
+			return
+		}
+		if existing.UserID != nil && *existing.UserID != user.ID {
+			writeError(w, "an overlay with this name belongs to another user", http.StatusForbidden)
+			return
+		}
+	}
+	
+	if err := overlay.SaveOverlay(cardOverlay, lensProcessor.Services().CardDB); err != nil {
+		writeError(w, fmt.Sprintf("failed to save overlay: %v", err), http.StatusBadRequest)
+		return
+	}
+	
+	writeResponse(w, cardOverlay)
+}
+
+// requestCardDB returns the card database for a request, with the overlay
+// given by ?overlay=<id> applied
+func requestCardDB(r *http.Request) (services.CardDatabase, error) {
+	cardDB := lensProcessor.Services().CardDB
+	
+	overlayParam := r.URL.Query().Get("overlay")
+	if overlayParam == "" {
+		return cardDB, nil
+	}
+	
+	if _, err := strconv.Atoi(overlayParam); err != nil {
+		return nil, fmt.Errorf("invalid overlay ID")
+	}
+	cardOverlay, err := visibleOverlay(r, overlayParam)
+	if err != nil {
+		return nil, err
+	}
+	return overlay.CardDB(cardOverlay.ID, cardDB)
+}
+
+// deckCardDB returns the card database decks of a format are checked
+// against: the format's errata overlays, then the overlay given by
+// ?overlay=<id or name>, so decks may use its custom and playtest cards
+func deckCardDB(r *http.Request, formatID string) (services.CardDatabase, error) {
+	cardDB := lensProcessor.Services().CardDB
+	
+	if formatID == "" {
+		formatID = format.DefaultFormatID
+	}
+	ids, err := overlay.FormatOverlayIDs(formatID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if overlayParam := r.URL.Query().Get("overlay"); overlayParam != "" {
+		cardOverlay, err := visibleOverlay(r, overlayParam)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, cardOverlay.ID)
+	}
+	
+	if len(ids) == 0 {
+		return cardDB, nil
+	}
+	return overlay.StackCardDB(ids, cardDB)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"quards/internal/auth"
+	"quards/internal/bot"
+	"quards/internal/game"
+
+	"github.com/gorilla/mux"
+)
+
+// ClaimSeatRequest accepts an invite to a seat
+type ClaimSeatRequest struct {
+	Token string `json:"token"`
+}
+
+// InviteRequest issues a fresh invite link for a free seat
+type InviteRequest struct {
+	Open bool `json:"open"` // Let anyone with the game link take the seat
+}
+
+// AssignBotRequest hands a seat to a bot
+type AssignBotRequest struct {
+	Bot string `json:"bot"` // Kind of bot, or empty to free the seat again
+}
+
+// SeatInvite is a seat with the link that claims it
+type SeatInvite struct {
+	game.Seat
+	InviteURL string `json:"inviteUrl"`
+}
+
+// inviteURL returns the viewer link that accepts an invite to a seat
+func inviteURL(seat game.Seat) string {
+	return fmt.Sprintf("/?game=%d&seat=%d&invite=%s", seat.GameID, seat.Seat, seat.InviteToken)
+}
+
+// seatErrorStatus maps seat errors to HTTP status codes
+func seatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, game.ErrNotSeated), errors.Is(err, game.ErrNotYourTurn), errors.Is(err, game.ErrBadInvite),
+		errors.Is(err, game.ErrNotCreator):
+		return http.StatusForbidden
+	case errors.Is(err, game.ErrSeatTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// seatVars parses the game ID and seat number from the route
+func seatVars(r *http.Request) (int, int, error) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid game ID")
+	}
+	seat, err := strconv.Atoi(vars["seat"])
+	if err != nil || seat < 1 || seat > 2 {
+		return 0, 0, fmt.Errorf("seat must be 1 or 2")
+	}
+	return gameID, seat, nil
+}
+
+// GameSeatsHandler lists a game's seats. Invite tokens are only shown to seated players.
+func GameSeatsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	seats, err := game.LoadSeats(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, game.HideInviteTokens(seats, auth.GetUserIDFromContext(r)))
+}
+
+// ClaimSeatHandler seats the requesting user, with the invite token unless the seat is open
+func ClaimSeatHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, seat, err := seatVars(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req ClaimSeatRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID := auth.GetUserIDFromContext(r)
+	if err := game.ClaimSeat(gameID, seat, userID, req.Token); err != nil {
+		writeError(w, err.Error(), seatErrorStatus(err))
+		return
+	}
+
+	seats, err := game.LoadSeats(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, seats)
+}
+
+// LeaveSeatHandler frees the requesting user's seat
+func LeaveSeatHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, seat, err := seatVars(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := game.LeaveSeat(gameID, seat, auth.GetUserIDFromContext(r)); err != nil {
+		writeError(w, err.Error(), seatErrorStatus(err))
+		return
+	}
+
+	writeResponse(w, map[string]string{"message": "left seat"})
+}
+
+// InviteToSeatHandler replaces a free seat's invite token and returns the new invite link
+func InviteToSeatHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, seat, err := seatVars(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req InviteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	invited, err := game.InviteToSeat(gameID, seat, auth.GetUserIDFromContext(r), req.Open)
+	if err != nil {
+		writeError(w, err.Error(), seatErrorStatus(err))
+		return
+	}
+
+	writeResponse(w, SeatInvite{Seat: *invited, InviteURL: inviteURL(*invited)})
+}
+
+// AssignBotHandler hands a free seat to a bot, which the server then plays
+func AssignBotHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, seat, err := seatVars(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req AssignBotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Bot != "" && !bot.IsKind(req.Bot) {
+		writeError(w, fmt.Sprintf("unknown bot %q, expected one of %v", req.Bot, bot.Kinds), http.StatusBadRequest)
+		return
+	}
+
+	userID := auth.GetUserIDFromContext(r)
+	if err := game.AssignBot(gameID, seat, userID, req.Bot); err != nil {
+		writeError(w, err.Error(), seatErrorStatus(err))
+		return
+	}
+	playBots(gameID)
+
+	seats, err := game.LoadSeats(gameID)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to load seats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, game.HideInviteTokens(seats, userID))
+}
+
+// playBots lets the game's bots take their turns in the background
+func playBots(gameID int) {
+	go bot.PlayTurns(gameID)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"quards/internal/auth"
+	"quards/internal/game"
+
+	"github.com/gorilla/mux"
+)
+
+// takebackErrorStatus maps takeback errors to HTTP status codes
+func takebackErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, game.ErrInvalidTakeback):
+		return http.StatusBadRequest
+	case errors.Is(err, game.ErrNotSeated), errors.Is(err, game.ErrNotOpponent):
+		return http.StatusForbidden
+	case errors.Is(err, game.ErrTakebackPending), errors.Is(err, game.ErrTakebackResolved), errors.Is(err, game.ErrTakebackStale):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// takebackVars parses the game and takeback IDs from the route
+func takebackVars(r *http.Request) (int, int, error) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid game ID")
+	}
+	takebackID, err := strconv.Atoi(vars["takeback"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid takeback ID")
+	}
+	return gameID, takebackID, nil
+}
+
+// redactTakebacks hides the removed entries the requesting user may not see.
+// Delayed spectators see none, as they may not have reached them yet.
+func redactTakebacks(r *http.Request, gameID int, takebacks []game.Takeback) ([]game.Takeback, error) {
+	gameData, err := game.LoadGame(gameID)
+	if err != nil {
+		return nil, err
+	}
+	view, err := gameView(r, gameData)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range takebacks {
+		if view.Delayed {
+			takebacks[i].RemovedLog = ""
+		} else {
+			takebacks[i].RemovedLog = game.RedactLog(takebacks[i].RemovedLog, view.Viewer)
+		}
+	}
+	return takebacks, nil
+}
+
+// writeTakeback writes a single takeback as the requesting user may see it
+func writeTakeback(w http.ResponseWriter, r *http.Request, takeback *game.Takeback) {
+	redacted, err := redactTakebacks(r, takeback.GameID, []game.Takeback{*takeback})
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	writeResponse(w, redacted[0])
+}
+
+// UndoHandler takes a game back to its first ?toStep=N log entries. The
+// takeback is recorded for auditing; in games with two seated users it is
+// pending until the opponent approves it.
+func UndoHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	toStep, err := strconv.Atoi(r.URL.Query().Get("toStep"))
+	if err != nil {
+		writeError(w, "toStep is required", http.StatusBadRequest)
+		return
+	}
+
+	takeback, err := game.RequestTakeback(gameID, auth.GetUserIDFromContext(r), toStep)
+	if err != nil {
+		writeError(w, err.Error(), takebackErrorStatus(err))
+		return
+	}
+
+	if takeback.Status == game.TakebackApplied {
+		playBots(gameID)
+	}
+	writeTakeback(w, r, takeback)
+}
+
+// ListTakebacksHandler returns a game's takeback audit trail
+func ListTakebacksHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	gameID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, "invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	takebacks, err := game.ListTakebacks(gameID)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	redacted, err := redactTakebacks(r, gameID, takebacks)
+	if err != nil {
+		writeError(w, err.Error(), viewErrorStatus(err))
+		return
+	}
+	writeResponse(w, redacted)
+}
+
+// ApproveTakebackHandler applies a pending takeback as the opponent
+func ApproveTakebackHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, takebackID, err := takebackVars(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	takeback, err := game.ApproveTakeback(gameID, takebackID, auth.GetUserIDFromContext(r))
+	if err != nil {
+		writeError(w, err.Error(), takebackErrorStatus(err))
+		return
+	}
+
+	if takeback.Status == game.TakebackApplied {
+		playBots(gameID)
+	}
+	writeTakeback(w, r, takeback)
+}
+
+// RejectTakebackHandler declines a pending takeback, or withdraws it when sent by the requester
+func RejectTakebackHandler(w http.ResponseWriter, r *http.Request) {
+	gameID, takebackID, err := takebackVars(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	takeback, err := game.RejectTakeback(gameID, takebackID, auth.GetUserIDFromContext(r))
+	if err != nil {
+		writeError(w, err.Error(), takebackErrorStatus(err))
+		return
+	}
+
+	writeTakeback(w, r, takeback)
+}
@@ -54,6 +54,21 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// RequireRole returns middleware that requires an authenticated user holding at least the given role
+func (a *AuthMiddleware) RequireRole(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return a.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetUserFromContext(r)
+			if user == nil || !user.HasRole(role) {
+				writeAuthError(w, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
 // OptionalAuth is middleware that optionally authenticates the user
 func (a *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -65,8 +80,22 @@ func (a *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 	})
 }
 
-// authenticateRequest attempts to authenticate a request
+// authenticateRequest attempts to authenticate a request. Deactivated users are rejected.
 func (a *AuthMiddleware) authenticateRequest(r *http.Request) (*User, error) {
+	user, err := a.resolveUser(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, fmt.Errorf("user %d is deactivated", user.ID)
+	}
+
+	return user, nil
+}
+
+// resolveUser finds the user a request belongs to
+func (a *AuthMiddleware) resolveUser(r *http.Request) (*User, error) {
 	// Dev mode bypass
 	if a.devMode {
 		if user, err := LoadUserByID(a.devUserID); err == nil {
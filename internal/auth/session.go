@@ -81,6 +81,18 @@ func DeleteSession(sessionToken string) error {
 	return nil
 }
 
+// DeleteUserSessions deletes all sessions belonging to a user
+func DeleteUserSessions(userID int) error {
+	db := database.GetDB()
+
+	_, err := db.Exec(`DELETE FROM user_sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+
+	return nil
+}
+
 // CleanupExpiredSessions removes expired sessions from the database
 func CleanupExpiredSessions() error {
 	db := database.GetDB()
@@ -9,6 +9,34 @@ import (
 	"quards/internal/database"
 )
 
+// Role represents a user's permission level
+type Role string
+
+// Roles in increasing order of privilege
+const (
+	RolePlayer    Role = "player"
+	RoleOrganizer Role = "organizer"
+	RoleAdmin     Role = "admin"
+)
+
+// roleRank orders roles so that higher roles include the permissions of lower ones
+var roleRank = map[Role]int{
+	RolePlayer:    1,
+	RoleOrganizer: 2,
+	RoleAdmin:     3,
+}
+
+// IsValid returns true if the role is one of the known roles
+func (r Role) IsValid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// Includes returns true if this role grants at least the permissions of the other role
+func (r Role) Includes(other Role) bool {
+	return roleRank[r] >= roleRank[other] && roleRank[other] > 0
+}
+
 // User represents a user in the system
 type User struct {
 	ID           int                    `json:"id"`
@@ -19,6 +47,7 @@ type User struct {
 	Provider     string                 `json:"provider"`
 	ProviderID   string                 `json:"providerId"`
 	ProviderData map[string]interface{} `json:"providerData,omitempty"`
+	Role         Role                   `json:"role"`
 	IsActive     bool                   `json:"isActive"`
 	CreatedAt    time.Time              `json:"createdAt"`
 	ModifiedAt   time.Time              `json:"modifiedAt"`
@@ -83,10 +112,10 @@ func LoadUserByID(userID int) (*User, error) {
 	var providerDataJSON []byte
 	err := db.QueryRow(`
 		SELECT id, username, display_name, email, avatar_url, provider, provider_id, 
-		       provider_data, is_active, created_at, modified_at, last_login_at
+		       provider_data, role, is_active, created_at, modified_at, last_login_at
 		FROM users WHERE id = $1`, userID).Scan(
 		&user.ID, &user.Username, &user.DisplayName, &user.Email, &user.AvatarURL,
-		&user.Provider, &user.ProviderID, &providerDataJSON, &user.IsActive,
+		&user.Provider, &user.ProviderID, &providerDataJSON, &user.Role, &user.IsActive,
 		&user.CreatedAt, &user.ModifiedAt, &user.LastLoginAt)
 
 	if err != nil {
@@ -138,4 +167,88 @@ func UpdateLastLogin(userID int) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// HasRole returns true if the user's role includes the given role
+func (u *User) HasRole(role Role) bool {
+	return u.Role.Includes(role)
+}
+
+// ListUsers returns all users, ordered by creation date
+func ListUsers() ([]User, error) {
+	db := database.GetDB()
+
+	rows, err := db.Query(`
+		SELECT id, username, display_name, email, avatar_url, provider, provider_id,
+		       role, is_active, created_at, modified_at, last_login_at
+		FROM users ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		err := rows.Scan(&user.ID, &user.Username, &user.DisplayName, &user.Email, &user.AvatarURL,
+			&user.Provider, &user.ProviderID, &user.Role, &user.IsActive,
+			&user.CreatedAt, &user.ModifiedAt, &user.LastLoginAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// SetUserActive activates or deactivates a user. Deactivating a user also
+// ends all of their sessions.
+func SetUserActive(userID int, active bool) error {
+	db := database.GetDB()
+
+	result, err := db.Exec(`UPDATE users SET is_active = $2 WHERE id = $1`, userID, active)
+	if err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found: %d", userID)
+	}
+
+	if !active {
+		return DeleteUserSessions(userID)
+	}
+
+	return nil
+}
+
+// SetUserRole changes a user's role
+func SetUserRole(userID int, role Role) error {
+	if !role.IsValid() {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+
+	db := database.GetDB()
+
+	result, err := db.Exec(`UPDATE users SET role = $2 WHERE id = $1`, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found: %d", userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package bot plays games: choosing actions for a seat from what it can see
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+
+	"quards/internal/evaluator"
+	"quards/internal/game"
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// Kinds of bot
+const (
+	KindRandom = "random" // Uniformly random valid actions
+	KindGreedy = "greedy" // The action that most improves the evaluator's score
+	KindSearch = "search" // Sampled search over whole turns and the opponent's reply
+)
+
+// Kinds lists every kind of bot
+var Kinds = []string{KindRandom, KindGreedy, KindSearch}
+
+// maxTurnActions bounds the actions a simulated greedy turn may take
+const maxTurnActions = 40
+
+// Player chooses the next action for the player to act
+type Player interface {
+	// ChooseAction picks one of the given valid actions. Pass is always among them.
+	ChooseAction(position *Position, actions []core.Action) (core.Action, error)
+}
+
+// Position is what a bot knows when choosing an action
+type Position struct {
+	Player   int                    // Player the bot acts for
+	Entries  []parser.LogEntry      // The log as the player sees it
+	State    *core.GameState        // The position as the player sees it
+	Services *services.LensServices // Lens services seen from the player's seat
+	Decks    game.DeckSource        // Both players' deck lists, for sampling hidden cards; may be nil
+}
+
+// NewPosition returns the position after the given entries as player sees it
+func NewPosition(entries []parser.LogEntry, player int, svc *services.LensServices, decks game.DeckSource) *Position {
+	viewer := &services.Viewer{Player: player}
+	viewServices := *svc
+	viewServices.Viewer = viewer
+	return &Position{
+		Player:   player,
+		Entries:  core.RedactEntries(entries, viewer),
+		State:    core.ReadGameState(entries, &viewServices),
+		Services: &viewServices,
+		Decks:    decks,
+	}
+}
+
+// ValidActions returns the valid actions of the player to act
+func ValidActions(entries []parser.LogEntry, svc *services.LensServices) []core.Action {
+	var actions []core.Action
+	for _, action := range core.AvailableActions(entries, svc) {
+		if action.Valid {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// New creates a bot of the given kind. Bots draw their randomness from rng,
+// so a bot given the same seed plays the same game the same way.
+func New(kind string, rng *rand.Rand) (Player, error) {
+	switch kind {
+	case KindRandom:
+		return NewRandom(rng), nil
+	case KindGreedy:
+		return NewGreedy(evaluator.NewHeuristic()), nil
+	case KindSearch:
+		return NewSearch(evaluator.NewHeuristic(), rng), nil
+	}
+	return nil, fmt.Errorf("unknown bot %q, expected one of %v", kind, Kinds)
+}
+
+// IsKind reports whether kind names a bot
+func IsKind(kind string) bool {
+	for _, known := range Kinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// passAction returns the pass among actions
+func passAction(actions []core.Action) core.Action {
+	for _, action := range actions {
+		if action.Type == "pass" {
+			return action
+		}
+	}
+	return core.Action{Type: "pass", Parameters: map[string]interface{}{}, Valid: true}
+}
+
+// score evaluates the position after entries for player
+func score(entries []parser.LogEntry, svc *services.LensServices, eval evaluator.Evaluator, player int) float64 {
+	return eval.Evaluate(core.ReadGameState(entries, svc), player)
+}
+
+// greedyChoice returns the action that most improves player's score, with
+// the entries after it, or a pass and nil when no action improves on passing
+func greedyChoice(entries []parser.LogEntry, svc *services.LensServices, eval evaluator.Evaluator, player int, actions []core.Action) (core.Action, []parser.LogEntry, error) {
+	best := passAction(actions)
+	var bestEntries []parser.LogEntry
+	bestScore := score(entries, svc, eval, player)
+
+	for _, action := range actions {
+		if action.Type == "pass" {
+			continue
+		}
+		next, err := game.ApplyAction(entries, action.Type, action.Parameters, player, svc, nil)
+		if err != nil {
+			return best, nil, err
+		}
+		if nextScore := score(next, svc, eval, player); nextScore > bestScore {
+			best, bestEntries, bestScore = action, next, nextScore
+		}
+	}
+	return best, bestEntries, nil
+}
+
+// greedyTurn plays the rest of the current player's turn greedily, up to
+// but not including the pass
+func greedyTurn(entries []parser.LogEntry, svc *services.LensServices, eval evaluator.Evaluator, player int) ([]parser.LogEntry, error) {
+	for i := 0; i < maxTurnActions; i++ {
+		_, next, err := greedyChoice(entries, svc, eval, player, ValidActions(entries, svc))
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			break
+		}
+		entries = next
+	}
+	return entries, nil
+}
@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"quards/internal/evaluator"
+	"quards/internal/lens/core"
+)
+
+// Greedy looks one action ahead: it takes the action whose resulting
+// position the evaluator scores highest, and passes once none improves on
+// the current position
+type Greedy struct {
+	Evaluator evaluator.Evaluator
+}
+
+// NewGreedy creates a greedy bot
+func NewGreedy(eval evaluator.Evaluator) *Greedy {
+	return &Greedy{Evaluator: eval}
+}
+
+// ChooseAction picks the action with the best one-ply score
+func (g *Greedy) ChooseAction(position *Position, actions []core.Action) (core.Action, error) {
+	action, _, err := greedyChoice(position.Entries, position.Services, g.Evaluator, position.Player, actions)
+	return action, err
+}
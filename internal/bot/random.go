@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"math/rand"
+
+	"quards/internal/lens/core"
+)
+
+// Random picks uniformly among the valid actions, passing included
+type Random struct {
+	rng *rand.Rand
+}
+
+// NewRandom creates a random bot
+func NewRandom(rng *rand.Rand) *Random {
+	return &Random{rng: rng}
+}
+
+// ChooseAction picks a random valid action
+func (r *Random) ChooseAction(position *Position, actions []core.Action) (core.Action, error) {
+	if len(actions) == 0 {
+		return passAction(actions), nil
+	}
+	return actions[r.rng.Intn(len(actions))], nil
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"quards/internal/game"
+	"quards/internal/lens/core"
+	"quards/internal/parser"
+)
+
+// maxRunActions bounds the actions bots take in one run, in case two bots
+// keep passing to each other without either winning
+const maxRunActions = 500
+
+// runner plays the bot seats of one game, one run at a time
+type runner struct {
+	mutex   sync.Mutex
+	running bool
+	again   bool              // Another run was requested while running
+	players map[string]Player // Bots by seat and kind, kept so search plans survive between actions
+}
+
+var (
+	runners      = make(map[int]*runner)
+	runnersMutex sync.Mutex
+)
+
+// PlayTurns plays the game's bot seats for as long as a bot is to act and
+// nobody has won. Calls while the game's bots are already playing make
+// them check again once done, so callers may trigger it after any change.
+func PlayTurns(gameID int) {
+	runnersMutex.Lock()
+	r, exists := runners[gameID]
+	if !exists {
+		r = &runner{players: make(map[string]Player)}
+		runners[gameID] = r
+	}
+	runnersMutex.Unlock()
+
+	r.mutex.Lock()
+	if r.running {
+		r.again = true
+		r.mutex.Unlock()
+		return
+	}
+	r.running = true
+	r.mutex.Unlock()
+
+	for {
+		over, err := r.play(gameID)
+		if err != nil {
+			log.Printf("bot: game %d: %v", gameID, err)
+		}
+
+		r.mutex.Lock()
+		if !r.again {
+			r.running = false
+			r.mutex.Unlock()
+			if over {
+				forget(gameID, r)
+			}
+			return
+		}
+		r.again = false
+		r.mutex.Unlock()
+	}
+}
+
+// play takes bot actions until a human is to act or the game is won, and
+// reports whether the game is over
+func (r *runner) play(gameID int) (bool, error) {
+	for i := 0; i < maxRunActions; i++ {
+		gameData, err := game.LoadGame(gameID)
+		if err != nil {
+			return false, err
+		}
+		if gameData.Status == "completed" {
+			return true, nil
+		}
+		seats, err := game.LoadSeats(gameID)
+		if err != nil {
+			return false, err
+		}
+		entries, err := parser.ParseLogContent(gameData.LogContent)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse game log: %w", err)
+		}
+		processor, err := game.LensProcessor(gameData)
+		if err != nil {
+			return false, err
+		}
+
+		state := core.ReadGameState(entries, processor.Services())
+		if state.Winner() != 0 {
+			return true, nil
+		}
+		kind := ""
+		for _, seat := range seats {
+			if seat.Seat == state.CurrentPlayer {
+				kind = seat.Bot
+			}
+		}
+		if kind == "" {
+			return false, nil
+		}
+
+		player, err := r.player(state.CurrentPlayer, kind)
+		if err != nil {
+			return false, err
+		}
+		position := NewPosition(entries, state.CurrentPlayer, processor.Services(), game.GameDecks(gameData, entries))
+		action, err := player.ChooseAction(position, ValidActions(position.Entries, position.Services))
+		if err != nil {
+			return false, err
+		}
+		// A human action or takeback may have landed while the bot was thinking
+		err = game.AppendBotAction(gameID, state.CurrentPlayer, len(entries), action.Type, action.Parameters)
+		if errors.Is(err, game.ErrGameMoved) || errors.Is(err, game.ErrNotYourTurn) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, fmt.Errorf("stopped after %d bot actions", maxRunActions)
+}
+
+// forget drops the runner of a finished game
+func forget(gameID int, r *runner) {
+	runnersMutex.Lock()
+	defer runnersMutex.Unlock()
+	if runners[gameID] == r {
+		delete(runners, gameID)
+	}
+}
+
+// player returns the bot playing a seat, creating it on first use
+func (r *runner) player(seat int, kind string) (Player, error) {
+	key := fmt.Sprintf("%d:%s", seat, kind)
+	if player, exists := r.players[key]; exists {
+		return player, nil
+	}
+	player, err := New(kind, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		return nil, err
+	}
+	r.players[key] = player
+	return player, nil
+}
@@ -0,0 +1,247 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"quards/internal/evaluator"
+	"quards/internal/explorer"
+	"quards/internal/game"
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// Search plans whole turns. It explores the lines of its turn, keeps the
+// ones the evaluator ranks highest, and plays each against sampled
+// opponent hands and library orders, with the opponent replying greedily.
+// The line with the best average score after the reply is played out action
+// by action.
+type Search struct {
+	Evaluator  evaluator.Evaluator
+	Budget     explorer.Budget // Explorer budget for the bot's own turn
+	Candidates int             // Best ranked lines played against the samples
+	Samples    int             // Hidden information samples per line
+
+	rng      *rand.Rand
+	plan     []explorer.Step // Rest of the chosen line, ending with the pass
+	planStep int             // Log length the next planned action is for
+}
+
+// NewSearch creates a search bot with a budget that keeps a decision under a second or so
+func NewSearch(eval evaluator.Evaluator, rng *rand.Rand) *Search {
+	return &Search{
+		Evaluator:  eval,
+		Budget:     explorer.Budget{MaxDepth: 12, MaxNodes: 300},
+		Candidates: 6,
+		Samples:    8,
+		rng:        rng,
+	}
+}
+
+// ChooseAction plays the next action of the planned line, planning the
+// turn first when there is no plan for this position
+func (s *Search) ChooseAction(position *Position, actions []core.Action) (core.Action, error) {
+	if action, ok := s.nextPlanned(position, actions); ok {
+		return action, nil
+	}
+
+	result, err := explorer.Explore(position.Entries, position.Services, s.Budget)
+	if err != nil {
+		return core.Action{}, err
+	}
+	lines := evaluator.Rank(result, s.Evaluator)
+	if len(lines) > s.Candidates {
+		lines = lines[:s.Candidates]
+	}
+
+	// Every line is played against the same samples
+	seeds := make([]int64, s.Samples)
+	for i := range seeds {
+		seeds[i] = s.rng.Int63()
+	}
+	full := *position.Services
+	full.Viewer = nil
+
+	best, bestScore := 0, math.Inf(-1)
+	for i, line := range lines {
+		total := 0.0
+		for _, seed := range seeds {
+			sampled := determinize(line.Entries, position.Player, position.Decks, rand.New(rand.NewSource(seed)))
+			replyScore, err := s.reply(sampled, &full, position)
+			if err != nil {
+				return core.Action{}, err
+			}
+			total += replyScore
+		}
+		if average := total / float64(len(seeds)); average > bestScore {
+			best, bestScore = i, average
+		}
+	}
+
+	s.plan = append(append([]explorer.Step{}, lines[best].Actions...), explorer.Step{Type: "pass", Parameters: map[string]interface{}{}})
+	s.planStep = len(position.Entries)
+	if action, ok := s.nextPlanned(position, actions); ok {
+		return action, nil
+	}
+	return passAction(actions), nil
+}
+
+// nextPlanned returns the next action of the plan if it was made for this
+// position and the action is still valid
+func (s *Search) nextPlanned(position *Position, actions []core.Action) (core.Action, bool) {
+	if len(s.plan) == 0 || len(position.Entries) != s.planStep {
+		s.plan = nil
+		return core.Action{}, false
+	}
+
+	step := s.plan[0]
+	for _, action := range actions {
+		if action.Type == step.Type && sameParameters(action.Parameters, step.Parameters) {
+			s.plan = s.plan[1:]
+			s.planStep++
+			return action, true
+		}
+	}
+	s.plan = nil
+	return core.Action{}, false
+}
+
+// reply passes the turn in a sampled position, lets the opponent play their
+// turn greedily and scores the result for the bot
+func (s *Search) reply(entries []parser.LogEntry, svc *services.LensServices, position *Position) (float64, error) {
+	if core.ReadGameState(entries, svc).Winner() != 0 {
+		return score(entries, svc, s.Evaluator, position.Player), nil
+	}
+
+	entries, err := game.ApplyAction(entries, "pass", nil, position.Player, svc, position.Decks)
+	if err != nil {
+		return 0, err
+	}
+	entries, err = greedyTurn(entries, svc, s.Evaluator, 3-position.Player)
+	if err != nil {
+		return 0, err
+	}
+	return score(entries, svc, s.Evaluator, position.Player), nil
+}
+
+// sameParameters compares action parameters by their JSON form
+func sameParameters(a, b map[string]interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// hiddenSlot is a card the player cannot see entering the opponent's hand:
+// one of an opening hand, or a draw when index is -1
+type hiddenSlot struct {
+	entry int
+	index int
+}
+
+// determinize returns the entries with the cards hidden from player filled
+// in, so the position can be played on with full information. Cards the
+// opponent has since played or inked take the earliest hidden slots; the
+// rest are drawn at random from the opponent's deck list less those cards.
+// The game's seed is replaced, which reshuffles both libraries.
+func determinize(entries []parser.LogEntry, player int, decks game.DeckSource, rng *rand.Rand) []parser.LogEntry {
+	opponent := 3 - player
+	handKey := fmt.Sprintf("p%d", opponent)
+
+	var open []hiddenSlot // Slots not yet known to hold a revealed card
+	cards := make(map[hiddenSlot]string)
+	revealed := make(map[string]int)
+	for i, entry := range entries {
+		switch entry.Event {
+		case parser.OpeningHandsDrawn:
+			if _, visible := entry.Parameters[handKey]; !visible {
+				for j := 0; j < entry.GetInt(handKey+"_count"); j++ {
+					open = append(open, hiddenSlot{entry: i, index: j})
+				}
+			}
+		case parser.CardDrawn:
+			if entry.GetPlayer() == opponent && entry.GetCard("card") == "" && entry.GetCard("card_id") == "" {
+				open = append(open, hiddenSlot{entry: i, index: -1})
+			}
+		case parser.CardPlayed, parser.CardInked:
+			if entry.GetPlayer() != opponent {
+				continue
+			}
+			cardID := entry.GetCard("card_id")
+			revealed[cardID]++
+			if len(open) > 0 {
+				cards[open[0]] = cardID
+				open = open[1:]
+			}
+		}
+	}
+
+	// The rest of the hidden cards come from what is left of the deck
+	var pool []string
+	if decks != nil {
+		if deckCards, err := decks(opponent); err == nil {
+			cardIDs := make([]string, 0, len(deckCards))
+			for cardID := range deckCards {
+				cardIDs = append(cardIDs, cardID)
+			}
+			sort.Strings(cardIDs)
+			for _, cardID := range cardIDs {
+				for n := deckCards[cardID] - revealed[cardID]; n > 0; n-- {
+					pool = append(pool, cardID)
+				}
+			}
+		}
+	}
+	rng.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+	for i, slot := range open {
+		if i < len(pool) {
+			cards[slot] = pool[i]
+		}
+	}
+
+	result := make([]parser.LogEntry, len(entries))
+	copy(result, entries)
+	openingHands := make(map[int][]string)
+	for slot, cardID := range cards {
+		if slot.index < 0 {
+			result[slot.entry] = withParameter(result[slot.entry], "card_id", cardID)
+			continue
+		}
+		hand := openingHands[slot.entry]
+		if hand == nil {
+			hand = make([]string, entries[slot.entry].GetInt(handKey+"_count"))
+		}
+		hand[slot.index] = cardID
+		openingHands[slot.entry] = hand
+	}
+	for i, hand := range openingHands {
+		result[i] = withParameter(result[i], handKey, strings.Join(hand, ","))
+		delete(result[i].Parameters, handKey+"_count")
+	}
+
+	seed := strconv.Itoa(rng.Intn(math.MaxInt32))
+	for i, entry := range result {
+		if entry.Event == parser.GameStarted {
+			result[i] = withParameter(entry, "seed", seed)
+		}
+	}
+	return result
+}
+
+// withParameter returns a copy of the entry with a parameter set
+func withParameter(entry parser.LogEntry, key, value string) parser.LogEntry {
+	parameters := make(map[string]string, len(entry.Parameters)+1)
+	for k, v := range entry.Parameters {
+		parameters[k] = v
+	}
+	parameters[key] = value
+	entry.Parameters = parameters
+	return entry
+}
@@ -0,0 +1,216 @@
+package cards
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"quards/internal/lens/services"
+)
+
+// Record is one card exactly as it appears in the canonical card file. Fields
+// the server does not model (e.g. Date_Added) are preserved.
+type Record map[string]interface{}
+
+// ID returns the card's Unique_ID
+func (r Record) ID() string {
+	id, _ := r["Unique_ID"].(string)
+	return id
+}
+
+// CardData decodes the record into the model used by the server and lenses
+func (r Record) CardData() (*services.CardData, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var card services.CardData
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, err
+	}
+	card.Normalize()
+	return &card, nil
+}
+
+// ReadFile loads records from a canonical card file
+func ReadFile(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// WriteFile writes records sorted by Unique_ID. The file is replaced
+// atomically so a running server never reads a partial file.
+func WriteFile(path string, records []Record) error {
+	sorted := make([]Record, len(records))
+	copy(sorted, records)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID() < sorted[j].ID()
+	})
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sorted); err != nil {
+		return fmt.Errorf("encode cards: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cards-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Problem describes a card that failed validation
+type Problem struct {
+	Index  int    `json:"index"` // Position in the imported file
+	CardID string `json:"cardId,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// Validate checks that every record can be used by the server
+func Validate(records []Record) []Problem {
+	var problems []Problem
+	seen := make(map[string]int)
+
+	for i, record := range records {
+		id := record.ID()
+		if id == "" {
+			problems = append(problems, Problem{Index: i, Detail: "missing Unique_ID"})
+			continue
+		}
+		if first, exists := seen[id]; exists {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: fmt.Sprintf("duplicate Unique_ID (first at index %d)", first)})
+			continue
+		}
+		seen[id] = i
+
+		card, err := record.CardData()
+		if err != nil {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: fmt.Sprintf("invalid field: %v", err)})
+			continue
+		}
+		if card.Name == "" {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: "missing Name"})
+		}
+		if card.Type == "" {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: "missing Type"})
+		}
+		if len(card.Colors) == 0 {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: "missing Color"})
+		}
+		if _, exists := record["Cost"]; !exists || card.Cost < 0 {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: "missing or negative Cost"})
+		}
+	}
+
+	return problems
+}
+
+// Change lists the fields that differ for one card
+type Change struct {
+	CardID string   `json:"cardId"`
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
+// Report summarizes the differences between two card sets
+type Report struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []Change `json:"changed"`
+}
+
+// HasChanges reports whether the card sets differ
+func (r *Report) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Changed) > 0
+}
+
+// Diff compares the current card set with an incoming one
+func Diff(current, incoming []Record) *Report {
+	report := &Report{Added: []string{}, Removed: []string{}, Changed: []Change{}}
+
+	currentByID := indexByID(current)
+	incomingByID := indexByID(incoming)
+
+	for _, id := range sortedIDs(incomingByID) {
+		before, exists := currentByID[id]
+		if !exists {
+			report.Added = append(report.Added, id)
+			continue
+		}
+		if fields := changedFields(before, incomingByID[id]); len(fields) > 0 {
+			name, _ := incomingByID[id]["Name"].(string)
+			report.Changed = append(report.Changed, Change{CardID: id, Name: name, Fields: fields})
+		}
+	}
+
+	for _, id := range sortedIDs(currentByID) {
+		if _, exists := incomingByID[id]; !exists {
+			report.Removed = append(report.Removed, id)
+		}
+	}
+
+	return report
+}
+
+func indexByID(records []Record) map[string]Record {
+	byID := make(map[string]Record, len(records))
+	for _, record := range records {
+		byID[record.ID()] = record
+	}
+	return byID
+}
+
+func sortedIDs(byID map[string]Record) []string {
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// changedFields returns the sorted names of fields that differ between two records
+func changedFields(before, after Record) []string {
+	var fields []string
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
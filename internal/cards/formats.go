@@ -0,0 +1,46 @@
+package cards
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Importer converts a card dump in some source format into records
+type Importer func(data []byte) ([]Record, error)
+
+// FormatLorcanaAPI is the bulk dump from https://api.lorcana-api.com/bulk/cards
+const FormatLorcanaAPI = "lorcana-api"
+
+var importers = map[string]Importer{
+	FormatLorcanaAPI: importLorcanaAPI,
+}
+
+// Import parses a card dump in the named format
+func Import(format string, data []byte) ([]Record, error) {
+	importer, exists := importers[format]
+	if !exists {
+		return nil, fmt.Errorf("unknown card format %q (available: %v)", format, Formats())
+	}
+	return importer(data)
+}
+
+// Formats returns the names of the supported source formats
+func Formats() []string {
+	names := make([]string, 0, len(importers))
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// importLorcanaAPI reads a JSON array of cards. The lorcana-api field names
+// are already the canonical ones, so records pass through unchanged.
+func importLorcanaAPI(data []byte) ([]Record, error) {
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("decode lorcana-api dump: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,72 @@
+package cards
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// ReadTable loads records from the cards table
+func ReadTable(db *sql.DB) ([]Record, error) {
+	rows, err := db.Query("SELECT data FROM cards ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cards: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode card: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// WriteTable applies a diff report to the cards table in one transaction, so
+// listening servers reload once with the complete card set
+func WriteTable(db *sql.DB, records []Record, report *Report) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	byID := indexByID(records)
+	upserts := append(append([]string{}, report.Added...), changedIDs(report)...)
+	for _, id := range upserts {
+		data, err := json.Marshal(byID[id])
+		if err != nil {
+			return fmt.Errorf("failed to encode card %s: %w", id, err)
+		}
+		_, err = tx.Exec(`
+			INSERT INTO cards (id, data) VALUES ($1, $2)
+			ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, modified_at = NOW()`,
+			id, data)
+		if err != nil {
+			return fmt.Errorf("failed to save card %s: %w", id, err)
+		}
+	}
+
+	for _, id := range report.Removed {
+		if _, err := tx.Exec("DELETE FROM cards WHERE id = $1", id); err != nil {
+			return fmt.Errorf("failed to remove card %s: %w", id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func changedIDs(report *Report) []string {
+	ids := make([]string, len(report.Changed))
+	for i, change := range report.Changed {
+		ids[i] = change.CardID
+	}
+	return ids
+}
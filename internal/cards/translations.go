@@ -0,0 +1,78 @@
+package cards
+
+import (
+	"fmt"
+)
+
+// translatedFields are the card fields copied from a translated dump
+var translatedFields = []string{"Name", "Body_Text", "Flavor_Text"}
+
+// MergeTranslations returns a copy of current with the text of each translated
+// record stored under its Translations entry for the language. Translated
+// records must match an existing Unique_ID.
+func MergeTranslations(current []Record, language string, translated []Record) ([]Record, []Problem) {
+	if language == "" {
+		return nil, []Problem{{Index: -1, Detail: "language is required"}}
+	}
+
+	merged := make([]Record, len(current))
+	positions := make(map[string]int, len(current))
+	for i, record := range current {
+		merged[i] = record
+		positions[record.ID()] = i
+	}
+
+	var problems []Problem
+	for i, record := range translated {
+		id := record.ID()
+		position, exists := positions[id]
+		if !exists {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: "no card with this Unique_ID"})
+			continue
+		}
+
+		text := make(map[string]interface{})
+		for _, field := range translatedFields {
+			if value, ok := record[field].(string); ok && value != "" {
+				text[field] = value
+			}
+		}
+		if text["Name"] == nil {
+			problems = append(problems, Problem{Index: i, CardID: id, Detail: "missing Name"})
+			continue
+		}
+
+		// Copy the record and its translations so current is left untouched
+		updated := make(Record, len(merged[position])+1)
+		for key, value := range merged[position] {
+			updated[key] = value
+		}
+		translations := make(map[string]interface{})
+		if existing, ok := updated["Translations"].(map[string]interface{}); ok {
+			for lang, value := range existing {
+				translations[lang] = value
+			}
+		}
+		translations[language] = text
+		updated["Translations"] = translations
+		merged[position] = updated
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return merged, nil
+}
+
+// ValidateLanguage checks a language code such as "de" or "fr"
+func ValidateLanguage(language string) error {
+	if len(language) < 2 || len(language) > 3 {
+		return fmt.Errorf("invalid language code %q", language)
+	}
+	for _, r := range language {
+		if r < 'a' || r > 'z' {
+			return fmt.Errorf("invalid language code %q", language)
+		}
+	}
+	return nil
+}
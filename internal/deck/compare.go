@@ -0,0 +1,151 @@
+package deck
+
+import (
+	"sort"
+
+	"quards/internal/lens/services"
+)
+
+// DeckSummary identifies one side of a comparison
+type DeckSummary struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	CardCount int    `json:"cardCount"`
+}
+
+// CardComparison compares the copies of one card across two decks
+type CardComparison struct {
+	Name  string `json:"name"`
+	A     int    `json:"a"`
+	B     int    `json:"b"`
+	Delta int    `json:"delta"` // B - A
+}
+
+// CountComparison compares a single count across two decks
+type CountComparison struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// CurveComparison compares the number of cards at one ink cost
+type CurveComparison struct {
+	Cost int `json:"cost"`
+	A    int `json:"a"`
+	B    int `json:"b"`
+}
+
+// DeckComparison is a structured diff of two decks
+type DeckComparison struct {
+	A         DeckSummary                `json:"a"`
+	B         DeckSummary                `json:"b"`
+	Shared    []CardComparison           `json:"shared"`  // Cards in both decks
+	Changed   []CardComparison           `json:"changed"` // Shared cards with different counts
+	OnlyA     []CardComparison           `json:"onlyA"`
+	OnlyB     []CardComparison           `json:"onlyB"`
+	CostCurve []CurveComparison          `json:"costCurve"`
+	Colors    map[string]CountComparison `json:"colors"`
+	Types     map[string]CountComparison `json:"types"`
+	Inkable   CountComparison            `json:"inkable"`
+	Uninkable CountComparison            `json:"uninkable"`
+	StatsA    *DeckStats                 `json:"statsA"`
+	StatsB    *DeckStats                 `json:"statsB"`
+}
+
+// CompareDecks diffs two decks. Cards are matched by full name so different
+// printings of the same card count as the same card.
+func CompareDecks(a, b *Deck, cardDB services.CardDatabase) *DeckComparison {
+	countsA := countsByName(a.Cards, cardDB)
+	countsB := countsByName(b.Cards, cardDB)
+	statsA := ComputeStats(a.Cards, cardDB)
+	statsB := ComputeStats(b.Cards, cardDB)
+
+	comparison := &DeckComparison{
+		A:         DeckSummary{ID: a.ID, Name: a.Name, CardCount: a.GetCardCount()},
+		B:         DeckSummary{ID: b.ID, Name: b.Name, CardCount: b.GetCardCount()},
+		Shared:    []CardComparison{},
+		Changed:   []CardComparison{},
+		OnlyA:     []CardComparison{},
+		OnlyB:     []CardComparison{},
+		CostCurve: []CurveComparison{},
+		Colors:    compareCounts(statsA.Colors, statsB.Colors),
+		Types:     compareCounts(statsA.Types, statsB.Types),
+		Inkable:   CountComparison{A: statsA.Inkable, B: statsB.Inkable},
+		Uninkable: CountComparison{A: statsA.Uninkable, B: statsB.Uninkable},
+		StatsA:    statsA,
+		StatsB:    statsB,
+	}
+
+	for _, name := range unionKeys(countsA, countsB) {
+		card := CardComparison{Name: name, A: countsA[name], B: countsB[name]}
+		card.Delta = card.B - card.A
+
+		switch {
+		case card.A > 0 && card.B > 0:
+			comparison.Shared = append(comparison.Shared, card)
+			if card.Delta != 0 {
+				comparison.Changed = append(comparison.Changed, card)
+			}
+		case card.A > 0:
+			comparison.OnlyA = append(comparison.OnlyA, card)
+		default:
+			comparison.OnlyB = append(comparison.OnlyB, card)
+		}
+	}
+
+	costs := make(map[int]bool)
+	for cost := range statsA.CostCurve {
+		costs[cost] = true
+	}
+	for cost := range statsB.CostCurve {
+		costs[cost] = true
+	}
+	for cost := range costs {
+		comparison.CostCurve = append(comparison.CostCurve, CurveComparison{
+			Cost: cost,
+			A:    statsA.CostCurve[cost],
+			B:    statsB.CostCurve[cost],
+		})
+	}
+	sort.Slice(comparison.CostCurve, func(i, j int) bool {
+		return comparison.CostCurve[i].Cost < comparison.CostCurve[j].Cost
+	})
+
+	return comparison
+}
+
+// countsByName totals card copies by full card name, falling back to the card ID for unknown cards
+func countsByName(cards map[string]int, cardDB services.CardDatabase) map[string]int {
+	counts := make(map[string]int)
+	for cardID, count := range cards {
+		name := cardID
+		if cardData, exists := cardDB.GetCard(cardID); exists {
+			name = cardData.Name
+		}
+		counts[name] += count
+	}
+	return counts
+}
+
+// compareCounts pairs up two count maps
+func compareCounts(a, b map[string]int) map[string]CountComparison {
+	result := make(map[string]CountComparison)
+	for _, key := range unionKeys(a, b) {
+		result[key] = CountComparison{A: a[key], B: b[key]}
+	}
+	return result
+}
+
+// unionKeys returns the sorted keys present in either map
+func unionKeys(a, b map[string]int) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
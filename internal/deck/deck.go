@@ -7,17 +7,22 @@ import (
 	"time"
 	
 	"quards/internal/database"
+	"quards/internal/format"
+	"quards/internal/lens/services"
 )
 
-// Deck represents a constructed deck of 60 cards
+// Deck represents a constructed deck of at least 60 cards
 type Deck struct {
 	ID          int               `json:"id"`
 	Name        string            `json:"name"`
 	Description string            `json:"description"`
 	Cards       map[string]int    `json:"cards"`       // CardID -> Count
+	Format      string            `json:"format"`      // Target format ID
+	Version     int               `json:"version"`     // Latest saved version number
 	UserID      int               `json:"userId"`
 	Created     time.Time         `json:"created"`
 	Modified    time.Time         `json:"modified"`
+	Deleted     *time.Time        `json:"deleted,omitempty"` // Set when soft-deleted because games still use the deck
 }
 
 // DeckList represents a simplified deck list for API responses
@@ -26,25 +31,12 @@ type DeckList struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	CardCount   int       `json:"cardCount"`
+	Format      string    `json:"format"`
 	UserID      int       `json:"userId"`
 	Created     time.Time `json:"created"`
 	Modified    time.Time `json:"modified"`
 }
 
-// ValidateDeck ensures the deck has exactly 60 cards
-func (d *Deck) ValidateDeck() error {
-	totalCards := 0
-	for _, count := range d.Cards {
-		totalCards += count
-	}
-	
-	if totalCards != 60 {
-		return fmt.Errorf("deck must have exactly 60 cards, got %d", totalCards)
-	}
-	
-	return nil
-}
-
 // GetCardCount returns the total number of cards in the deck
 func (d *Deck) GetCardCount() int {
 	total := 0
@@ -54,9 +46,13 @@ func (d *Deck) GetCardCount() int {
 	return total
 }
 
-// SaveDeck saves a deck to the database
-func SaveDeck(deck *Deck) error {
-	if err := deck.ValidateDeck(); err != nil {
+// SaveDeck validates a deck against the card database and saves it. New
+// decks belong to deck.UserID; saving an existing name keeps its owner.
+func SaveDeck(deck *Deck, cardDB services.CardDatabase) error {
+	if deck.Format == "" {
+		deck.Format = format.DefaultFormatID
+	}
+	if err := deck.ValidateDeck(cardDB); err != nil {
 		return err
 	}
 	
@@ -68,34 +64,56 @@ func SaveDeck(deck *Deck) error {
 		return fmt.Errorf("failed to marshal cards: %w", err)
 	}
 	
+	// Save the deck and record the new version together
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
 	// Check if deck exists
 	var exists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM decks WHERE name = $1)", deck.Name).Scan(&exists)
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM decks WHERE name = $1 AND deleted_at IS NULL)", deck.Name).Scan(&exists)
 	if err != nil {
 		return fmt.Errorf("failed to check deck existence: %w", err)
 	}
 	
 	if exists {
 		// Update existing deck
-		_, err = db.Exec(`
+		err = tx.QueryRow(`
 			UPDATE decks 
-			SET description = $2, cards = $3, modified_at = NOW() 
-			WHERE name = $1`,
-			deck.Name, deck.Description, cardsJSON)
+			SET description = $2, cards = $3, format = $4, modified_at = NOW() 
+			WHERE name = $1 AND deleted_at IS NULL
+			RETURNING id`,
+			deck.Name, deck.Description, cardsJSON, deck.Format).Scan(&deck.ID)
 		if err != nil {
 			return fmt.Errorf("failed to update deck: %w", err)
 		}
 	} else {
-		// Insert new deck
-		_, err = db.Exec(`
-			INSERT INTO decks (name, description, cards) 
-			VALUES ($1, $2, $3)`,
-			deck.Name, deck.Description, cardsJSON)
+		// Insert new deck, owned by whoever saved it
+		var owner interface{}
+		if deck.UserID > 0 {
+			owner = deck.UserID
+		}
+		err = tx.QueryRow(`
+			INSERT INTO decks (name, description, cards, format, user_id) 
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`,
+			deck.Name, deck.Description, cardsJSON, deck.Format, owner).Scan(&deck.ID)
 		if err != nil {
 			return fmt.Errorf("failed to insert deck: %w", err)
 		}
 	}
 	
+	deck.Version, err = recordVersion(tx, deck, cardsJSON)
+	if err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	
 	return nil
 }
 
@@ -107,9 +125,11 @@ func LoadDeck(name string) (*Deck, error) {
 	var cardsJSON []byte
 	
 	err := db.QueryRow(`
-		SELECT id, name, description, cards, user_id, created_at, modified_at 
-		FROM decks WHERE name = $1`, name).Scan(
-		&deck.ID, &deck.Name, &deck.Description, &cardsJSON, &deck.UserID, &deck.Created, &deck.Modified)
+		SELECT id, name, description, cards, format, COALESCE(user_id, 0), created_at, modified_at,
+		       (SELECT COALESCE(MAX(version), 0) FROM deck_versions WHERE deck_id = decks.id)
+		FROM decks WHERE name = $1 AND deleted_at IS NULL`, name).Scan(
+		&deck.ID, &deck.Name, &deck.Description, &cardsJSON, &deck.Format, &deck.UserID, &deck.Created, &deck.Modified,
+		&deck.Version)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -126,7 +146,8 @@ func LoadDeck(name string) (*Deck, error) {
 	return &deck, nil
 }
 
-// LoadDeckByID loads a deck from the database by ID
+// LoadDeckByID loads a deck from the database by ID. Soft-deleted decks are
+// still returned so that games referencing them can be displayed.
 func LoadDeckByID(id int) (*Deck, error) {
 	db := database.GetDB()
 	
@@ -134,9 +155,11 @@ func LoadDeckByID(id int) (*Deck, error) {
 	var cardsJSON []byte
 	
 	err := db.QueryRow(`
-		SELECT id, name, description, cards, user_id, created_at, modified_at 
+		SELECT id, name, description, cards, format, COALESCE(user_id, 0), created_at, modified_at, deleted_at,
+		       (SELECT COALESCE(MAX(version), 0) FROM deck_versions WHERE deck_id = decks.id)
 		FROM decks WHERE id = $1`, id).Scan(
-		&deck.ID, &deck.Name, &deck.Description, &cardsJSON, &deck.UserID, &deck.Created, &deck.Modified)
+		&deck.ID, &deck.Name, &deck.Description, &cardsJSON, &deck.Format, &deck.UserID, &deck.Created, &deck.Modified,
+		&deck.Deleted, &deck.Version)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -158,8 +181,8 @@ func ListDecks() ([]DeckList, error) {
 	db := database.GetDB()
 	
 	rows, err := db.Query(`
-		SELECT id, name, description, cards, user_id, created_at, modified_at 
-		FROM decks ORDER BY created_at DESC`)
+		SELECT id, name, description, cards, format, COALESCE(user_id, 0), created_at, modified_at 
+		FROM decks WHERE deleted_at IS NULL ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query decks: %w", err)
 	}
@@ -168,11 +191,11 @@ func ListDecks() ([]DeckList, error) {
 	var decks []DeckList
 	for rows.Next() {
 		var id, userID int
-		var name, description string
+		var name, description, deckFormat string
 		var cardsJSON []byte
 		var created, modified time.Time
 		
-		err := rows.Scan(&id, &name, &description, &cardsJSON, &userID, &created, &modified)
+		err := rows.Scan(&id, &name, &description, &cardsJSON, &deckFormat, &userID, &created, &modified)
 		if err != nil {
 			continue // Skip invalid rows
 		}
@@ -193,6 +216,7 @@ func ListDecks() ([]DeckList, error) {
 			Name:        name,
 			Description: description,
 			CardCount:   cardCount,
+			Format:      deckFormat,
 			UserID:      userID,
 			Created:     created,
 			Modified:    modified,
@@ -202,13 +226,47 @@ func ListDecks() ([]DeckList, error) {
 	return decks, nil
 }
 
-// DeleteDeck removes a deck from the database
-func DeleteDeck(name string) error {
+// UpdateDeck validates and saves changes to an existing deck, including renames
+func UpdateDeck(id int, deck *Deck, cardDB services.CardDatabase) error {
+	if deck.Format == "" {
+		deck.Format = format.DefaultFormatID
+	}
+	if err := deck.ValidateDeck(cardDB); err != nil {
+		return err
+	}
+	
 	db := database.GetDB()
 	
-	result, err := db.Exec("DELETE FROM decks WHERE name = $1", name)
+	cardsJSON, err := json.Marshal(deck.Cards)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cards: %w", err)
+	}
+	
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Renaming must not collide with another active deck
+	var nameTaken bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM decks WHERE name = $1 AND id <> $2 AND deleted_at IS NULL)`,
+		deck.Name, id).Scan(&nameTaken)
+	if err != nil {
+		return fmt.Errorf("failed to check deck name: %w", err)
+	}
+	if nameTaken {
+		return fmt.Errorf("a deck named %s already exists", deck.Name)
+	}
+	
+	result, err := tx.Exec(`
+		UPDATE decks 
+		SET name = $2, description = $3, cards = $4, format = $5, modified_at = NOW() 
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id, deck.Name, deck.Description, cardsJSON, deck.Format)
 	if err != nil {
-		return fmt.Errorf("failed to delete deck: %w", err)
+		return fmt.Errorf("failed to update deck: %w", err)
 	}
 	
 	rowsAffected, err := result.RowsAffected()
@@ -217,19 +275,75 @@ func DeleteDeck(name string) error {
 	}
 	
 	if rowsAffected == 0 {
-		return fmt.Errorf("deck not found: %s", name)
+		return fmt.Errorf("deck not found with ID: %d", id)
+	}
+	
+	deck.ID = id
+	deck.Version, err = recordVersion(tx, deck, cardsJSON)
+	if err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	
 	return nil
 }
 
-// DeleteDeckByID removes a deck from the database by ID
-func DeleteDeckByID(id int) error {
+// DeleteDeck removes a deck from the database by name
+func DeleteDeck(name string) (bool, error) {
+	deck, err := LoadDeck(name)
+	if err != nil {
+		return false, err
+	}
+	
+	return DeleteDeckByID(deck.ID)
+}
+
+// DeleteDeckByID removes a deck from the database by ID. Decks still referenced
+// by games are soft-deleted instead, so those games keep their deck; the
+// returned bool reports whether that happened.
+func DeleteDeckByID(id int) (bool, error) {
+	db := database.GetDB()
+	
+	var gameCount int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM games WHERE player1_deck_id = $1 OR player2_deck_id = $1`, id).Scan(&gameCount)
+	if err != nil {
+		return false, fmt.Errorf("failed to check deck references: %w", err)
+	}
+	
+	softDelete := gameCount > 0
+	var result sql.Result
+	if softDelete {
+		result, err = db.Exec("UPDATE decks SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL", id)
+	} else {
+		result, err = db.Exec("DELETE FROM decks WHERE id = $1", id)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete deck: %w", err)
+	}
+	
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	
+	if rowsAffected == 0 {
+		return false, fmt.Errorf("deck not found with ID: %d", id)
+	}
+	
+	return softDelete, nil
+}
+
+// TransferDeckOwnership assigns a deck to a different user
+func TransferDeckOwnership(id, userID int) error {
 	db := database.GetDB()
 	
-	result, err := db.Exec("DELETE FROM decks WHERE id = $1", id)
+	result, err := db.Exec("UPDATE decks SET user_id = $2, modified_at = NOW() WHERE id = $1", id, userID)
 	if err != nil {
-		return fmt.Errorf("failed to delete deck: %w", err)
+		return fmt.Errorf("failed to transfer deck: %w", err)
 	}
 	
 	rowsAffected, err := result.RowsAffected()
@@ -242,4 +356,4 @@ func DeleteDeckByID(id int) error {
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,263 @@
+package deck
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"quards/internal/lens/services"
+)
+
+// OpeningHandSize is the number of cards each player draws before the first turn
+const OpeningHandSize = 7
+
+// Calculation methods for draw probabilities
+const (
+	MethodExact      = "exact"      // Hypergeometric distribution
+	MethodSimulation = "simulation" // Monte Carlo shuffles
+)
+
+const (
+	defaultTrials = 10000
+	maxTrials     = 1000000
+)
+
+// CardPredicate selects cards by their attributes. Empty fields match any card;
+// set fields must all match.
+type CardPredicate struct {
+	MinCost *int     `json:"minCost,omitempty"`
+	MaxCost *int     `json:"maxCost,omitempty"`
+	Types   []string `json:"types,omitempty"` // Base type or full type, e.g. "Character" or "Action - Song"
+	Inkable *bool    `json:"inkable,omitempty"`
+	Colors  []string `json:"colors,omitempty"` // Matches cards with any of these colors
+	Names   []string `json:"names,omitempty"`  // Case-insensitive substring of the full card name
+}
+
+// Matches reports whether a card satisfies the predicate
+func (p *CardPredicate) Matches(card *services.CardData) bool {
+	if p.MinCost != nil && card.Cost < *p.MinCost {
+		return false
+	}
+	if p.MaxCost != nil && card.Cost > *p.MaxCost {
+		return false
+	}
+	if p.Inkable != nil && card.Inkable != *p.Inkable {
+		return false
+	}
+
+	if len(p.Types) > 0 {
+		if !containsFold(p.Types, card.Type) && !containsFold(p.Types, card.BaseType()) {
+			return false
+		}
+	}
+
+	if len(p.Colors) > 0 {
+		matched := false
+		for _, color := range card.Colors {
+			if containsFold(p.Colors, color) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(p.Names) > 0 {
+		name := strings.ToLower(card.Name)
+		matched := false
+		for _, candidate := range p.Names {
+			if strings.Contains(name, strings.ToLower(candidate)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ProbabilityQuery asks for the chance of holding at least MinCount matching
+// cards by a given turn. Turn 0 is the opening hand.
+type ProbabilityQuery struct {
+	Predicate CardPredicate `json:"predicate"`
+	MinCount  int           `json:"minCount"`
+	Turn      int           `json:"turn"`
+	Method    string        `json:"method,omitempty"`
+	Trials    int           `json:"trials,omitempty"` // Simulation only
+	Seed      int64         `json:"seed,omitempty"`   // Simulation only
+}
+
+// ProbabilityResult holds the answer for both play orders. The player on the
+// play skips their first draw, so they see one fewer card by any given turn.
+type ProbabilityResult struct {
+	DeckSize      int      `json:"deckSize"`
+	Matching      int      `json:"matching"`
+	MatchingCards []string `json:"matchingCards"`
+	MinCount      int      `json:"minCount"`
+	Turn          int      `json:"turn"`
+	Method        string   `json:"method"`
+	Trials        int      `json:"trials,omitempty"`
+	SeenOnPlay    int      `json:"seenOnPlay"` // Cards seen by the turn's draw step
+	SeenOnDraw    int      `json:"seenOnDraw"`
+	OnPlay        float64  `json:"onPlay"`
+	OnDraw        float64  `json:"onDraw"`
+}
+
+// CardsSeen returns how many cards a player has drawn by the given turn
+func CardsSeen(turn int, onPlay bool) int {
+	if turn <= 0 {
+		return OpeningHandSize
+	}
+	if onPlay {
+		return OpeningHandSize + turn - 1
+	}
+	return OpeningHandSize + turn
+}
+
+// DrawProbability computes the chance of holding at least query.MinCount
+// cards matching query.Predicate by query.Turn
+func DrawProbability(cards map[string]int, cardDB services.CardDatabase, query ProbabilityQuery) (*ProbabilityResult, error) {
+	if query.MinCount <= 0 {
+		query.MinCount = 1
+	}
+	if query.Turn < 0 {
+		return nil, fmt.Errorf("turn must not be negative")
+	}
+	if query.Method == "" {
+		query.Method = MethodExact
+	}
+
+	result := &ProbabilityResult{
+		MatchingCards: []string{},
+		MinCount:      query.MinCount,
+		Turn:          query.Turn,
+		Method:        query.Method,
+	}
+
+	// Sorted IDs keep simulated shuffles reproducible for a given seed
+	cardIDs := make([]string, 0, len(cards))
+	for cardID := range cards {
+		cardIDs = append(cardIDs, cardID)
+	}
+	sort.Strings(cardIDs)
+
+	library := make([]bool, 0, len(cards)*4)
+	for _, cardID := range cardIDs {
+		count := cards[cardID]
+		if count <= 0 {
+			continue
+		}
+		matches := false
+		if cardData, exists := cardDB.GetCard(cardID); exists {
+			matches = query.Predicate.Matches(cardData)
+		}
+		if matches {
+			result.Matching += count
+			result.MatchingCards = append(result.MatchingCards, cardID)
+		}
+		for i := 0; i < count; i++ {
+			library = append(library, matches)
+		}
+	}
+	result.DeckSize = len(library)
+
+	result.SeenOnPlay = min(CardsSeen(query.Turn, true), result.DeckSize)
+	result.SeenOnDraw = min(CardsSeen(query.Turn, false), result.DeckSize)
+
+	switch query.Method {
+	case MethodExact:
+		result.OnPlay = atLeastHypergeometric(result.DeckSize, result.Matching, result.SeenOnPlay, query.MinCount)
+		result.OnDraw = atLeastHypergeometric(result.DeckSize, result.Matching, result.SeenOnDraw, query.MinCount)
+	case MethodSimulation:
+		trials := query.Trials
+		if trials <= 0 {
+			trials = defaultTrials
+		}
+		if trials > maxTrials {
+			return nil, fmt.Errorf("trials must be at most %d", maxTrials)
+		}
+		result.Trials = trials
+		result.OnPlay, result.OnDraw = simulateDraws(library, result.SeenOnPlay, result.SeenOnDraw, query.MinCount, trials, query.Seed)
+	default:
+		return nil, fmt.Errorf("unknown method %q", query.Method)
+	}
+
+	return result, nil
+}
+
+// atLeastHypergeometric returns P(X >= k) when drawing n cards from a deck of
+// size N containing K successes
+func atLeastHypergeometric(N, K, n, k int) float64 {
+	if k > n || k > K {
+		return 0
+	}
+	total := 0.0
+	for x := k; x <= min(n, K); x++ {
+		if n-x > N-K {
+			continue
+		}
+		total += math.Exp(logChoose(K, x) + logChoose(N-K, n-x) - logChoose(N, n))
+	}
+	return math.Min(total, 1)
+}
+
+// logChoose returns ln(n choose k)
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// simulateDraws shuffles the library repeatedly and counts how often the
+// first seen cards contain at least minCount matches
+func simulateDraws(library []bool, seenOnPlay, seenOnDraw, minCount, trials int, seed int64) (float64, float64) {
+	rng := rand.New(rand.NewSource(seed))
+	deck := make([]bool, len(library))
+	hitsOnPlay, hitsOnDraw := 0, 0
+
+	for trial := 0; trial < trials; trial++ {
+		copy(deck, library)
+		rng.Shuffle(len(deck), func(i, j int) {
+			deck[i], deck[j] = deck[j], deck[i]
+		})
+
+		matches := 0
+		for i := 0; i < seenOnPlay; i++ {
+			if deck[i] {
+				matches++
+			}
+		}
+		if matches >= minCount {
+			hitsOnPlay++
+		}
+
+		for i := seenOnPlay; i < seenOnDraw; i++ {
+			if deck[i] {
+				matches++
+			}
+		}
+		if matches >= minCount {
+			hitsOnDraw++
+		}
+	}
+
+	return float64(hitsOnPlay) / float64(trials), float64(hitsOnDraw) / float64(trials)
+}
+
+// containsFold reports whether values contains target, ignoring case
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package deck
+
+import (
+	"math"
+	"testing"
+
+	"quards/internal/lens/services"
+)
+
+func TestCardsSeen(t *testing.T) {
+	tests := []struct {
+		turn   int
+		onPlay bool
+		want   int
+	}{
+		{turn: 0, onPlay: true, want: 7},
+		{turn: 0, onPlay: false, want: 7},
+		{turn: 1, onPlay: true, want: 7},
+		{turn: 1, onPlay: false, want: 8},
+		{turn: 3, onPlay: true, want: 9},
+		{turn: 3, onPlay: false, want: 10},
+	}
+
+	for _, tt := range tests {
+		if got := CardsSeen(tt.turn, tt.onPlay); got != tt.want {
+			t.Errorf("CardsSeen(%d, %v) = %d, want %d", tt.turn, tt.onPlay, got, tt.want)
+		}
+	}
+}
+
+func TestAtLeastHypergeometric(t *testing.T) {
+	tests := []struct {
+		name       string
+		N, K, n, k int
+		want       float64
+	}{
+		{name: "one of four in an opening hand", N: 60, K: 4, n: 7, k: 1, want: 0.3995},
+		{name: "one of four in eight cards", N: 60, K: 4, n: 8, k: 1, want: 0.4448},
+		{name: "two of four in an opening hand", N: 60, K: 4, n: 7, k: 2, want: 0.0632},
+		{name: "one of twelve in an opening hand", N: 60, K: 12, n: 7, k: 1, want: 0.8094},
+		{name: "two of eight in ten cards", N: 60, K: 8, n: 10, k: 2, want: 0.3998},
+		{name: "no matching cards", N: 60, K: 0, n: 7, k: 1, want: 0},
+		{name: "more than are drawn", N: 60, K: 10, n: 7, k: 8, want: 0},
+		{name: "whole deck drawn", N: 10, K: 4, n: 10, k: 4, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := atLeastHypergeometric(tt.N, tt.K, tt.n, tt.k)
+			if math.Abs(got-tt.want) > 1e-4 {
+				t.Errorf("atLeastHypergeometric(%d, %d, %d, %d) = %.4f, want %.4f", tt.N, tt.K, tt.n, tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrawProbability(t *testing.T) {
+	cardDB, err := services.NewOverlayCardDB(services.NewInMemoryCardDB(), map[string]services.CardPatch{
+		"CHR-001": {"Name": "Test Hero", "Type": "Character", "Color": "Amber", "Cost": 1},
+		"ITM-001": {"Name": "Test Lantern", "Type": "Item", "Color": "Amber", "Cost": 1},
+	})
+	if err != nil {
+		t.Fatalf("NewOverlayCardDB: %v", err)
+	}
+	cards := map[string]int{"CHR-001": 4, "ITM-001": 56}
+	predicate := CardPredicate{Names: []string{"hero"}}
+
+	tests := []struct {
+		name           string
+		turn           int
+		onPlay, onDraw float64
+	}{
+		{name: "opening hand", turn: 0, onPlay: 0.3995, onDraw: 0.3995},
+		{name: "first turn", turn: 1, onPlay: 0.3995, onDraw: 0.4448},
+		{name: "second turn", turn: 2, onPlay: 0.4448, onDraw: 0.4875},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DrawProbability(cards, cardDB, ProbabilityQuery{Predicate: predicate, Turn: tt.turn})
+			if err != nil {
+				t.Fatalf("DrawProbability: %v", err)
+			}
+			if result.DeckSize != 60 || result.Matching != 4 {
+				t.Fatalf("deck size %d with %d matching, want 60 with 4", result.DeckSize, result.Matching)
+			}
+			if math.Abs(result.OnPlay-tt.onPlay) > 1e-4 || math.Abs(result.OnDraw-tt.onDraw) > 1e-4 {
+				t.Errorf("on the play %.4f, on the draw %.4f, want %.4f and %.4f",
+					result.OnPlay, result.OnDraw, tt.onPlay, tt.onDraw)
+			}
+
+			simulated, err := DrawProbability(cards, cardDB, ProbabilityQuery{
+				Predicate: predicate, Turn: tt.turn, Method: MethodSimulation, Trials: 20000, Seed: 1,
+			})
+			if err != nil {
+				t.Fatalf("DrawProbability simulation: %v", err)
+			}
+			if math.Abs(simulated.OnPlay-tt.onPlay) > 0.02 || math.Abs(simulated.OnDraw-tt.onDraw) > 0.02 {
+				t.Errorf("simulated on the play %.4f, on the draw %.4f, want about %.4f and %.4f",
+					simulated.OnPlay, simulated.OnDraw, tt.onPlay, tt.onDraw)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package deck
+
+import (
+	"quards/internal/lens/services"
+)
+
+// DeckStats summarizes the composition of a deck
+type DeckStats struct {
+	CardCount        int            `json:"cardCount"`
+	UnknownCards     int            `json:"unknownCards"` // Copies of cards missing from the card database
+	CostCurve        map[int]int    `json:"costCurve"`    // Ink cost -> number of cards
+	AverageCost      float64        `json:"averageCost"`
+	Inkable          int            `json:"inkable"`
+	Uninkable        int            `json:"uninkable"`
+	InkableRatio     float64        `json:"inkableRatio"`
+	Colors           map[string]int `json:"colors"` // Dual-ink cards count toward both colors
+	Types            map[string]int `json:"types"`  // Character, Action, Item, Location
+	Characters       int            `json:"characters"`
+	AverageLore      float64        `json:"averageLore"` // Averages are over characters only
+	AverageStrength  float64        `json:"averageStrength"`
+	AverageWillpower float64        `json:"averageWillpower"`
+	Songs            int            `json:"songs"`
+	Shift            int            `json:"shift"`
+	Keywords         map[string]int `json:"keywords"` // Keyword ability -> number of cards with it
+}
+
+// ComputeStats calculates statistics for a card list using the card database
+func ComputeStats(cards map[string]int, cardDB services.CardDatabase) *DeckStats {
+	stats := &DeckStats{
+		CostCurve: make(map[int]int),
+		Colors:    make(map[string]int),
+		Types:     make(map[string]int),
+		Keywords:  make(map[string]int),
+	}
+
+	totalCost, totalLore, totalStrength, totalWillpower := 0, 0, 0, 0
+	knownCards := 0
+
+	for cardID, count := range cards {
+		if count <= 0 {
+			continue
+		}
+		stats.CardCount += count
+
+		cardData, exists := cardDB.GetCard(cardID)
+		if !exists {
+			stats.UnknownCards += count
+			continue
+		}
+		knownCards += count
+
+		stats.CostCurve[cardData.Cost] += count
+		totalCost += cardData.Cost * count
+
+		if cardData.Inkable {
+			stats.Inkable += count
+		} else {
+			stats.Uninkable += count
+		}
+
+		for _, color := range cardData.Colors {
+			stats.Colors[color] += count
+		}
+
+		// "Action - Song" is an Action with the Song subtype
+		baseType := cardData.BaseType()
+		stats.Types[baseType] += count
+		if cardData.Subtype() == "Song" {
+			stats.Songs += count
+		}
+
+		if baseType == "Character" {
+			stats.Characters += count
+			totalLore += cardData.Lore * count
+			totalStrength += cardData.Strength * count
+			totalWillpower += cardData.Willpower * count
+		}
+
+		for _, keyword := range cardData.Keywords {
+			stats.Keywords[keyword] += count
+			if keyword == "Shift" {
+				stats.Shift += count
+			}
+		}
+	}
+
+	if knownCards > 0 {
+		stats.AverageCost = float64(totalCost) / float64(knownCards)
+		stats.InkableRatio = float64(stats.Inkable) / float64(knownCards)
+	}
+	if stats.Characters > 0 {
+		stats.AverageLore = float64(totalLore) / float64(stats.Characters)
+		stats.AverageStrength = float64(totalStrength) / float64(stats.Characters)
+		stats.AverageWillpower = float64(totalWillpower) / float64(stats.Characters)
+	}
+
+	return stats
+}
@@ -0,0 +1,277 @@
+package deck
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"quards/internal/format"
+	"quards/internal/lens/services"
+)
+
+// maxSuggestions limits how many near-miss card names are offered per unresolved line
+const maxSuggestions = 3
+
+// deckLinePattern matches "4 Rhino - Motivational Speaker" and "4x Rhino - Motivational Speaker"
+var deckLinePattern = regexp.MustCompile(`^(\d+)\s*[xX]?\s+(.+)$`)
+
+// sectionHeaderPattern matches the card type headings deck builders put
+// between sections, such as "Characters (20)" or "Songs:"
+var sectionHeaderPattern = regexp.MustCompile(`(?i)^(characters|actions|songs|items|locations|main deck|deck)\s*(\(\d+\))?\s*:?$`)
+
+// sideboardHeaderPattern matches headings of cards kept outside the deck,
+// such as "Sideboard (10)" or "Maybeboard:"
+var sideboardHeaderPattern = regexp.MustCompile(`(?i)^(sideboard|maybe\s*-?\s*board)\s*(\(\d+\))?\s*:?$`)
+
+// ImportLineError describes a line of a text deck list that could not be imported
+type ImportLineError struct {
+	Line        int      `json:"line"`
+	Text        string   `json:"text"`
+	Detail      string   `json:"detail"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// ImportResult is the outcome of parsing a text deck list
+type ImportResult struct {
+	Cards     map[string]int    `json:"cards"` // CardID -> Count
+	Errors    []ImportLineError `json:"errors"`
+	Sideboard []ImportLineError `json:"sideboard"` // Lines under a sideboard heading, left out of the deck
+}
+
+// ParseDeckText parses a community-style text deck list ("quantity full card name"
+// per line, as exported by Dreamborn or Inktable) and resolves each card name
+// against the card database. Name matching ignores case and apostrophe style.
+// Blank lines, comments and section headers such as "Characters (20)" are
+// skipped. Lines under a sideboard or maybe-board heading, up to the next
+// section header, are not imported but listed in Sideboard. When a name has
+// several printings, one legal in the target format is preferred; f may be
+// nil when there is no target format.
+func ParseDeckText(text string, cardDB services.CardDatabase, f *format.Format) *ImportResult {
+	index := newCardNameIndex(cardDB, f)
+	result := &ImportResult{
+		Cards:     make(map[string]int),
+		Errors:    []ImportLineError{},
+		Sideboard: []ImportLineError{},
+	}
+
+	inSideboard := false
+	for i, rawLine := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if sideboardHeaderPattern.MatchString(line) {
+			inSideboard = true
+			continue
+		}
+		if isSectionHeader(line) {
+			inSideboard = false
+			continue
+		}
+		if inSideboard {
+			result.Sideboard = append(result.Sideboard, ImportLineError{
+				Line:   i + 1,
+				Text:   line,
+				Detail: "sideboard cards are not part of the deck",
+			})
+			continue
+		}
+
+		match := deckLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			result.Errors = append(result.Errors, ImportLineError{
+				Line:   i + 1,
+				Text:   line,
+				Detail: `invalid format, expected "quantity card name"`,
+			})
+			continue
+		}
+
+		count, err := strconv.Atoi(match[1])
+		if err != nil || count <= 0 {
+			result.Errors = append(result.Errors, ImportLineError{
+				Line:   i + 1,
+				Text:   line,
+				Detail: fmt.Sprintf("invalid quantity %q", match[1]),
+			})
+			continue
+		}
+
+		name := strings.TrimSpace(match[2])
+		cardID, found := index.resolve(name)
+		if !found {
+			result.Errors = append(result.Errors, ImportLineError{
+				Line:        i + 1,
+				Text:        line,
+				Detail:      fmt.Sprintf("card %q not found in card database", name),
+				Suggestions: index.suggest(name, maxSuggestions),
+			})
+			continue
+		}
+
+		result.Cards[cardID] += count
+	}
+
+	return result
+}
+
+// ExportDeckText renders a deck as a community-style text deck list. Copies of
+// different printings of the same card are combined under the card's full name.
+func ExportDeckText(d *Deck, cardDB services.CardDatabase) string {
+	counts := make(map[string]int)
+	for cardID, count := range d.Cards {
+		name := cardID
+		if cardData, exists := cardDB.GetCard(cardID); exists {
+			name = cardData.Name
+		}
+		counts[name] += count
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&builder, "%d %s\n", counts[name], name)
+	}
+	return builder.String()
+}
+
+// isSectionHeader reports whether a line is a heading like "Characters (20)" or "Songs:"
+func isSectionHeader(line string) bool {
+	return sectionHeaderPattern.MatchString(line)
+}
+
+// cardNameIndex maps normalized full card names to card IDs
+type cardNameIndex struct {
+	ids   map[string]string // normalized name -> card ID
+	names map[string]string // normalized name -> display name
+}
+
+// newCardNameIndex builds a name index over the card database. When several
+// printings share a name, the index keeps the one preferred by
+// preferPrinting for the target format.
+func newCardNameIndex(cardDB services.CardDatabase, f *format.Format) *cardNameIndex {
+	index := &cardNameIndex{
+		ids:   make(map[string]string),
+		names: make(map[string]string),
+	}
+
+	chosen := make(map[string]*services.CardData)
+	for cardID, cardData := range cardDB.GetAll() {
+		key := normalizeCardName(cardData.Name)
+		if existing, exists := chosen[key]; !exists || preferPrinting(cardData, existing, f) {
+			chosen[key] = cardData
+			index.ids[key] = cardID
+			index.names[key] = cardData.Name
+		}
+	}
+
+	return index
+}
+
+// preferPrinting reports whether printing a should be imported rather than b.
+// Printings legal in the format come first, then regular printings before
+// enchanted ones, then the most recent set, so reprints of rotated cards
+// resolve to their current printing. Remaining ties go to the lower card number
+// and finally the lower card ID, so the choice never depends on map order.
+func preferPrinting(a, b *services.CardData, f *format.Format) bool {
+	if f != nil {
+		if legalA, legalB := f.AllowsSet(a.SetNum), f.AllowsSet(b.SetNum); legalA != legalB {
+			return legalA
+		}
+	}
+	if enchantedA, enchantedB := isEnchanted(a), isEnchanted(b); enchantedA != enchantedB {
+		return !enchantedA
+	}
+	if a.SetNum != b.SetNum {
+		return a.SetNum > b.SetNum
+	}
+	if a.CardNum != b.CardNum {
+		return a.CardNum < b.CardNum
+	}
+	return a.UniqueID < b.UniqueID
+}
+
+func isEnchanted(cardData *services.CardData) bool {
+	return strings.EqualFold(cardData.Rarity, "enchanted")
+}
+
+// resolve finds the card ID for a full card name
+func (idx *cardNameIndex) resolve(name string) (string, bool) {
+	cardID, found := idx.ids[normalizeCardName(name)]
+	return cardID, found
+}
+
+// suggest returns the closest card names to an unresolved name
+func (idx *cardNameIndex) suggest(name string, limit int) []string {
+	target := normalizeCardName(name)
+	maxDistance := len(target)/3 + 2
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+
+	for key, displayName := range idx.names {
+		distance := levenshtein(target, key)
+		if strings.Contains(key, target) {
+			// Partial names like "Rhino" should still find "Rhino - Motivational Speaker"
+			distance = 1
+		}
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{name: displayName, distance: distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// normalizeCardName lowercases a name and unifies apostrophes, dashes and whitespace
+func normalizeCardName(name string) string {
+	replacer := strings.NewReplacer("’", "'", "‘", "'", "–", "-", "—", "-")
+	name = strings.ToLower(replacer.Replace(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
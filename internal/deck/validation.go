@@ -0,0 +1,180 @@
+package deck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"quards/internal/format"
+	"quards/internal/lens/services"
+)
+
+// Validation rule identifiers
+const (
+	RuleDeckSize     = "deck_size"
+	RuleMaxCopies    = "max_copies"
+	RuleInkColors    = "ink_colors"
+	RuleUnknownCard  = "unknown_card"
+	RuleInvalidCount = "invalid_count"
+	RuleSetNotLegal  = "set_not_legal"
+	RuleBannedCard   = "banned_card"
+	RuleFormat       = "unknown_format"
+)
+
+// ValidationError describes a single deckbuilding rule violation
+type ValidationError struct {
+	Rule   string `json:"rule"`
+	Card   string `json:"card,omitempty"` // Card ID or full card name the violation refers to
+	Detail string `json:"detail"`
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	return e.Detail
+}
+
+// ValidationErrors collects every violation found in a deck
+type ValidationErrors []ValidationError
+
+// Error implements the error interface
+func (e ValidationErrors) Error() string {
+	details := make([]string, len(e))
+	for i, violation := range e {
+		details[i] = violation.Detail
+	}
+	return fmt.Sprintf("deck is not legal: %s", strings.Join(details, "; "))
+}
+
+// TargetFormat returns the format the deck is built for
+func (d *Deck) TargetFormat() (*format.Format, error) {
+	formatID := d.Format
+	if formatID == "" {
+		formatID = format.DefaultFormatID
+	}
+	f, exists := format.Get(formatID)
+	if !exists {
+		return nil, fmt.Errorf("unknown format: %s", formatID)
+	}
+	return f, nil
+}
+
+// ValidateDeck checks the deck against the deckbuilding rules of its format. It
+// returns ValidationErrors listing every violation, or nil if the deck is legal.
+func (d *Deck) ValidateDeck(cardDB services.CardDatabase) error {
+	if violations := d.Violations(cardDB); len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// Violations returns all deckbuilding rule violations for the deck in its format
+func (d *Deck) Violations(cardDB services.CardDatabase) ValidationErrors {
+	f, err := d.TargetFormat()
+	if err != nil {
+		return ValidationErrors{{Rule: RuleFormat, Detail: err.Error()}}
+	}
+	return d.ViolationsInFormat(cardDB, f)
+}
+
+// ViolationsInFormat returns all deckbuilding rule violations for the deck in the given format
+func (d *Deck) ViolationsInFormat(cardDB services.CardDatabase, f *format.Format) ValidationErrors {
+	violations := ValidationErrors{}
+
+	// Sort card IDs so violations are reported in a stable order
+	cardIDs := make([]string, 0, len(d.Cards))
+	for cardID := range d.Cards {
+		cardIDs = append(cardIDs, cardID)
+	}
+	sort.Strings(cardIDs)
+
+	totalCards := 0
+	copiesByName := make(map[string]int)
+	var names []string
+	colors := make(map[string]bool)
+
+	for _, cardID := range cardIDs {
+		count := d.Cards[cardID]
+		if count <= 0 {
+			violations = append(violations, ValidationError{
+				Rule:   RuleInvalidCount,
+				Card:   cardID,
+				Detail: fmt.Sprintf("card %s has invalid count %d", cardID, count),
+			})
+			continue
+		}
+		totalCards += count
+
+		cardData, exists := cardDB.GetCard(cardID)
+		if !exists {
+			violations = append(violations, ValidationError{
+				Rule:   RuleUnknownCard,
+				Card:   cardID,
+				Detail: fmt.Sprintf("card %s not found in card database", cardID),
+			})
+			continue
+		}
+
+		if !f.AllowsSet(cardData.SetNum) {
+			violations = append(violations, ValidationError{
+				Rule:   RuleSetNotLegal,
+				Card:   cardID,
+				Detail: fmt.Sprintf("%s (%s, set %d) is not legal in %s", cardData.Name, cardID, cardData.SetNum, f.Name),
+			})
+		}
+
+		// Copy limits apply across all versions of a card sharing the same full name
+		if _, seen := copiesByName[cardData.Name]; !seen {
+			names = append(names, cardData.Name)
+		}
+		copiesByName[cardData.Name] += count
+
+		for _, color := range cardData.Colors {
+			colors[color] = true
+		}
+	}
+
+	if totalCards < f.MinDeckSize {
+		violations = append(violations, ValidationError{
+			Rule:   RuleDeckSize,
+			Detail: fmt.Sprintf("deck must have at least %d cards, got %d", f.MinDeckSize, totalCards),
+		})
+	}
+	if f.MaxDeckSize > 0 && totalCards > f.MaxDeckSize {
+		violations = append(violations, ValidationError{
+			Rule:   RuleDeckSize,
+			Detail: fmt.Sprintf("deck may have at most %d cards, got %d", f.MaxDeckSize, totalCards),
+		})
+	}
+
+	for _, name := range names {
+		if f.IsBanned(name) {
+			violations = append(violations, ValidationError{
+				Rule:   RuleBannedCard,
+				Card:   name,
+				Detail: fmt.Sprintf("%s is banned in %s", name, f.Name),
+			})
+			continue
+		}
+		if limit, copies := f.CopyLimit(name), copiesByName[name]; copies > limit {
+			violations = append(violations, ValidationError{
+				Rule:   RuleMaxCopies,
+				Card:   name,
+				Detail: fmt.Sprintf("deck may contain at most %d copies of %s in %s, got %d", limit, name, f.Name, copies),
+			})
+		}
+	}
+
+	if len(colors) > f.MaxInkColors {
+		colorList := make([]string, 0, len(colors))
+		for color := range colors {
+			colorList = append(colorList, color)
+		}
+		sort.Strings(colorList)
+		violations = append(violations, ValidationError{
+			Rule:   RuleInkColors,
+			Detail: fmt.Sprintf("deck may use at most %d ink colors, got %d (%s)", f.MaxInkColors, len(colorList), strings.Join(colorList, ", ")),
+		})
+	}
+
+	return violations
+}
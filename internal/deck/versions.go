@@ -0,0 +1,231 @@
+package deck
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"quards/internal/database"
+)
+
+// DeckVersion is a snapshot of a deck taken every time it is saved
+type DeckVersion struct {
+	ID          int            `json:"id"`
+	DeckID      int            `json:"deckId"`
+	Version     int            `json:"version"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Format      string         `json:"format"`
+	Cards       map[string]int `json:"cards"` // CardID -> Count
+	CardCount   int            `json:"cardCount"`
+	Created     time.Time      `json:"created"`
+}
+
+// CardChange is the change in copies of a single card between two versions
+type CardChange struct {
+	CardID string `json:"cardId"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+	Count  int    `json:"count"` // Number of copies added or removed
+}
+
+// DeckDiff describes the card changes between two versions of a deck
+type DeckDiff struct {
+	DeckID       int          `json:"deckId"`
+	From         int          `json:"from"`
+	To           int          `json:"to"`
+	Added        []CardChange `json:"added"`
+	Removed      []CardChange `json:"removed"`
+	TotalAdded   int          `json:"totalAdded"`
+	TotalRemoved int          `json:"totalRemoved"`
+}
+
+// recordVersion stores the deck's current contents as its next version. The
+// deck row is locked first so concurrent saves cannot pick the same number.
+func recordVersion(tx *sql.Tx, deck *Deck, cardsJSON []byte) (int, error) {
+	if _, err := tx.Exec(`SELECT id FROM decks WHERE id = $1 FOR UPDATE`, deck.ID); err != nil {
+		return 0, fmt.Errorf("failed to lock deck: %w", err)
+	}
+
+	var version int
+	err := tx.QueryRow(`
+		INSERT INTO deck_versions (deck_id, version, name, description, format, cards)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5
+		FROM deck_versions WHERE deck_id = $1
+		RETURNING version`,
+		deck.ID, deck.Name, deck.Description, deck.Format, cardsJSON).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record deck version: %w", err)
+	}
+	return version, nil
+}
+
+// ListVersions returns every saved version of a deck, newest first
+func ListVersions(deckID int) ([]DeckVersion, error) {
+	db := database.GetDB()
+
+	rows, err := db.Query(`
+		SELECT id, deck_id, version, name, description, format, cards, created_at
+		FROM deck_versions WHERE deck_id = $1 ORDER BY version DESC`, deckID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deck versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []DeckVersion{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+
+	return versions, rows.Err()
+}
+
+// LoadVersion loads a specific version of a deck by its version number
+func LoadVersion(deckID, version int) (*DeckVersion, error) {
+	db := database.GetDB()
+
+	row := db.QueryRow(`
+		SELECT id, deck_id, version, name, description, format, cards, created_at
+		FROM deck_versions WHERE deck_id = $1 AND version = $2`, deckID, version)
+	deckVersion, err := scanVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("version %d not found for deck %d", version, deckID)
+	}
+	return deckVersion, err
+}
+
+// LoadVersionByID loads a deck version by its unique ID, as pinned by games
+func LoadVersionByID(id int) (*DeckVersion, error) {
+	db := database.GetDB()
+
+	row := db.QueryRow(`
+		SELECT id, deck_id, version, name, description, format, cards, created_at
+		FROM deck_versions WHERE id = $1`, id)
+	deckVersion, err := scanVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("deck version not found with ID: %d", id)
+	}
+	return deckVersion, err
+}
+
+// LatestVersionID returns the ID of the most recent version of a deck
+func LatestVersionID(deckID int) (int, error) {
+	db := database.GetDB()
+
+	var id int
+	err := db.QueryRow(`
+		SELECT id FROM deck_versions WHERE deck_id = $1
+		ORDER BY version DESC LIMIT 1`, deckID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("no versions recorded for deck %d", deckID)
+		}
+		return 0, fmt.Errorf("failed to load latest deck version: %w", err)
+	}
+	return id, nil
+}
+
+// DiffVersions compares two versions of a deck. Version 0 stands for the empty
+// deck before the first save, so diffing a new deck lists every card as added.
+func DiffVersions(deckID, from, to int) (*DeckDiff, error) {
+	fromCards, err := versionCards(deckID, from)
+	if err != nil {
+		return nil, err
+	}
+	toCards, err := versionCards(deckID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffCards(fromCards, toCards)
+	diff.DeckID = deckID
+	diff.From = from
+	diff.To = to
+	return diff, nil
+}
+
+// versionCards returns the cards of a deck version, or none for version 0
+func versionCards(deckID, version int) (map[string]int, error) {
+	if version == 0 {
+		return map[string]int{}, nil
+	}
+	deckVersion, err := LoadVersion(deckID, version)
+	if err != nil {
+		return nil, err
+	}
+	return deckVersion.Cards, nil
+}
+
+// DiffCards computes the per-card additions and removals between two card lists
+func DiffCards(from, to map[string]int) *DeckDiff {
+	diff := &DeckDiff{
+		Added:   []CardChange{},
+		Removed: []CardChange{},
+	}
+
+	cardIDs := make(map[string]bool)
+	for cardID := range from {
+		cardIDs[cardID] = true
+	}
+	for cardID := range to {
+		cardIDs[cardID] = true
+	}
+
+	sortedIDs := make([]string, 0, len(cardIDs))
+	for cardID := range cardIDs {
+		sortedIDs = append(sortedIDs, cardID)
+	}
+	sort.Strings(sortedIDs)
+
+	for _, cardID := range sortedIDs {
+		change := CardChange{CardID: cardID, From: from[cardID], To: to[cardID]}
+		switch {
+		case change.To > change.From:
+			change.Count = change.To - change.From
+			diff.Added = append(diff.Added, change)
+			diff.TotalAdded += change.Count
+		case change.To < change.From:
+			change.Count = change.From - change.To
+			diff.Removed = append(diff.Removed, change)
+			diff.TotalRemoved += change.Count
+		}
+	}
+
+	return diff
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanVersion reads a deck version from a query result
+func scanVersion(row rowScanner) (*DeckVersion, error) {
+	var version DeckVersion
+	var cardsJSON []byte
+
+	err := row.Scan(&version.ID, &version.DeckID, &version.Version, &version.Name,
+		&version.Description, &version.Format, &cardsJSON, &version.Created)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load deck version: %w", err)
+	}
+
+	if err := json.Unmarshal(cardsJSON, &version.Cards); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cards: %w", err)
+	}
+
+	for _, count := range version.Cards {
+		version.CardCount += count
+	}
+
+	return &version, nil
+}
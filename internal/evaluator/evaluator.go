@@ -0,0 +1,150 @@
+// Package evaluator scores game positions and ranks the lines reaching them
+package evaluator
+
+import (
+	"sort"
+
+	"quards/internal/explorer"
+	"quards/internal/lens/core"
+)
+
+// Evaluator scores a position for a player. Higher scores are better for
+// that player; scores are only compared between positions of the same game.
+type Evaluator interface {
+	Evaluate(state *core.GameState, player int) float64
+}
+
+// WinScore is the score of a won position, above any heuristic score
+const WinScore = 1e6
+
+// Terms are the heuristic's measures of a position, each the player's value
+// less the opponent's
+type Terms struct {
+	Lore       float64 `json:"lore"`
+	Strength   float64 `json:"strength"`   // Total strength of characters in play
+	Willpower  float64 `json:"willpower"`  // Total willpower of characters in play
+	Ink        float64 `json:"ink"`        // Cards in the inkwell
+	Hand       float64 `json:"hand"`       // Cards in hand
+	Threatened float64 `json:"threatened"` // Exerted characters the other side could banish in a challenge
+}
+
+// Weights scale the heuristic's terms
+type Weights Terms
+
+// DefaultWeights favour lore above all, then cards and board
+var DefaultWeights = Weights{
+	Lore:       10,
+	Strength:   1,
+	Willpower:  0.5,
+	Ink:        2,
+	Hand:       1.5,
+	Threatened: -3,
+}
+
+// Heuristic is the default evaluator, a weighted sum of Terms
+type Heuristic struct {
+	Weights Weights
+}
+
+// NewHeuristic returns a heuristic evaluator with the default weights
+func NewHeuristic() *Heuristic {
+	return &Heuristic{Weights: DefaultWeights}
+}
+
+// Evaluate scores a position for a player
+func (h *Heuristic) Evaluate(state *core.GameState, player int) float64 {
+	switch state.Winner() {
+	case 0:
+	case player:
+		return WinScore
+	default:
+		return -WinScore
+	}
+
+	terms := h.Terms(state, player)
+	return terms.Lore*h.Weights.Lore +
+		terms.Strength*h.Weights.Strength +
+		terms.Willpower*h.Weights.Willpower +
+		terms.Ink*h.Weights.Ink +
+		terms.Hand*h.Weights.Hand +
+		terms.Threatened*h.Weights.Threatened
+}
+
+// Terms measures a position for a player
+func (h *Heuristic) Terms(state *core.GameState, player int) Terms {
+	own, opponent := state.Player(player), state.Opponent(player)
+	ownStrength, ownWillpower := boardTotals(own)
+	opponentStrength, opponentWillpower := boardTotals(opponent)
+
+	return Terms{
+		Lore:       float64(own.Lore - opponent.Lore),
+		Strength:   float64(ownStrength - opponentStrength),
+		Willpower:  float64(ownWillpower - opponentWillpower),
+		Ink:        float64(own.TotalInk - opponent.TotalInk),
+		Hand:       float64(own.HandCount - opponent.HandCount),
+		Threatened: float64(threatened(own, opponent) - threatened(opponent, own)),
+	}
+}
+
+// boardTotals sums the strength and willpower of a player's characters
+func boardTotals(player *core.PlayerState) (int, int) {
+	strength, willpower := 0, 0
+	for _, card := range player.InPlay {
+		if card.Type == "Character" {
+			strength += card.Strength
+			willpower += card.Willpower
+		}
+	}
+	return strength, willpower
+}
+
+// threatened counts the exerted characters of a player that one of the
+// other player's characters is strong enough to banish in a challenge
+func threatened(player, other *core.PlayerState) int {
+	strongest := 0
+	for _, card := range other.InPlay {
+		if card.Type == "Character" && card.Strength > strongest {
+			strongest = card.Strength
+		}
+	}
+
+	count := 0
+	for _, card := range player.InPlay {
+		if card.Type == "Character" && card.Exhausted && card.Willpower <= strongest {
+			count++
+		}
+	}
+	return count
+}
+
+// RankedLine is an explored line with the score of the position it ends in
+type RankedLine struct {
+	Rank  int     `json:"rank"`
+	Score float64 `json:"score"`
+	Terms *Terms  `json:"terms,omitempty"` // Set for the default heuristic
+	explorer.EndState
+}
+
+// Rank scores every end state of an exploration for the exploring player,
+// best first. Ties keep the explorer's order, so shorter lines come first.
+func Rank(result *explorer.Result, evaluator Evaluator) []RankedLine {
+	lines := make([]RankedLine, len(result.EndStates))
+	for i, endState := range result.EndStates {
+		lines[i] = RankedLine{
+			Score:    evaluator.Evaluate(endState.State, result.Player),
+			EndState: endState,
+		}
+		if heuristic, ok := evaluator.(*Heuristic); ok {
+			terms := heuristic.Terms(endState.State, result.Player)
+			lines[i].Terms = &terms
+		}
+	}
+
+	sort.SliceStable(lines, func(a, b int) bool {
+		return lines[a].Score > lines[b].Score
+	})
+	for i := range lines {
+		lines[i].Rank = i + 1
+	}
+	return lines
+}
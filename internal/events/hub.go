@@ -0,0 +1,99 @@
+package events
+
+import (
+	"sync"
+)
+
+// Event types published for a game
+const (
+	TypeAppend = "append" // Entries were appended to the log
+	TypeReset  = "reset"  // The log was rewritten (e.g. truncated); clients should resync
+)
+
+// Entry is a log entry as delivered to subscribers
+type Entry struct {
+	Step       int               `json:"step"`
+	Event      string            `json:"event"`
+	Player     int               `json:"player"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// GameEvent describes a change to a game's log
+type GameEvent struct {
+	Type    string      `json:"type"`
+	GameID  int         `json:"gameId"`
+	Step    int         `json:"step"`    // Number of log entries after the change
+	Entries []Entry     `json:"entries"` // Newly appended entries
+	State   interface{} `json:"state"`   // Composite game state after the change
+}
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it
+const subscriberBuffer = 16
+
+// Hub fans out game events to in-process subscribers
+type Hub struct {
+	subscribers map[int]map[chan GameEvent]struct{}
+	mutex       sync.RWMutex
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[int]map[chan GameEvent]struct{}),
+	}
+}
+
+// Default is the hub shared by the game package and the API
+var Default = NewHub()
+
+// Subscribe returns a channel of events for a game and a function that
+// unsubscribes and closes the channel
+func (h *Hub) Subscribe(gameID int) (<-chan GameEvent, func()) {
+	ch := make(chan GameEvent, subscriberBuffer)
+
+	h.mutex.Lock()
+	if h.subscribers[gameID] == nil {
+		h.subscribers[gameID] = make(map[chan GameEvent]struct{})
+	}
+	h.subscribers[gameID][ch] = struct{}{}
+	h.mutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mutex.Lock()
+			delete(h.subscribers[gameID], ch)
+			if len(h.subscribers[gameID]) == 0 {
+				delete(h.subscribers, gameID)
+			}
+			h.mutex.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// HasSubscribers reports whether anyone is listening to a game, so
+// publishers can skip computing events nobody will receive
+func (h *Hub) HasSubscribers(gameID int) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.subscribers[gameID]) > 0
+}
+
+// Publish delivers an event to every subscriber of its game without blocking.
+// Subscribers whose buffer is full miss the event and can resync from the
+// step numbers of later events.
+func (h *Hub) Publish(event GameEvent) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for ch := range h.subscribers[event.GameID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package explorer enumerates the lines a player can take within a turn
+package explorer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"quards/internal/game"
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// ErrHiddenHand is returned when the explorer cannot see the hand of the player to act
+var ErrHiddenHand = errors.New("the current player's hand is hidden from this view")
+
+// Budget bounds an exploration
+type Budget struct {
+	MaxDepth int `json:"maxDepth"` // Most actions in a line
+	MaxNodes int `json:"maxNodes"` // Most positions expanded
+}
+
+// DefaultBudget is large enough for an ordinary turn
+var DefaultBudget = Budget{MaxDepth: 12, MaxNodes: 2000}
+
+// Step is one action of a line
+type Step struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// EndState is a distinct position the player can pass the turn in, with the
+// shortest line reaching it
+type EndState struct {
+	Hash    string            `json:"hash"`
+	Actions []Step            `json:"actions"`
+	State   *core.GameState   `json:"state"`
+	Entries []parser.LogEntry `json:"-"` // The log up to the pass
+}
+
+// Result is the outcome of exploring a turn
+type Result struct {
+	Player    int        `json:"player"`
+	Turn      int        `json:"turn"`
+	Explored  int        `json:"explored"`  // Positions expanded
+	Truncated bool       `json:"truncated"` // The budget ran out before every line was explored
+	EndStates []EndState `json:"endStates"`
+}
+
+// node is a position waiting to be expanded
+type node struct {
+	entries []parser.LogEntry
+	actions []Step
+}
+
+// Explore enumerates breadth-first every sequence of valid actions the
+// current player can take before passing the turn. Positions reached by
+// different lines are kept once, with the first and so shortest line found.
+// Doing nothing is always the first end state.
+func Explore(entries []parser.LogEntry, svc *services.LensServices, budget Budget) (*Result, error) {
+	if budget.MaxDepth <= 0 {
+		budget.MaxDepth = DefaultBudget.MaxDepth
+	}
+	if budget.MaxNodes <= 0 {
+		budget.MaxNodes = DefaultBudget.MaxNodes
+	}
+
+	root := core.ReadGameState(entries, svc)
+	if !svc.Viewer.CanSee(root.CurrentPlayer) {
+		return nil, ErrHiddenHand
+	}
+
+	result := &Result{
+		Player:    root.CurrentPlayer,
+		Turn:      root.Turn,
+		EndStates: []EndState{},
+	}
+	seen := make(map[string]bool)
+
+	rootHash, err := Hash(root)
+	if err != nil {
+		return nil, err
+	}
+	seen[rootHash] = true
+	result.EndStates = append(result.EndStates, EndState{Hash: rootHash, Actions: []Step{}, State: root, Entries: entries})
+
+	queue := []node{{entries: entries, actions: []Step{}}}
+	for len(queue) > 0 {
+		if result.Explored >= budget.MaxNodes {
+			result.Truncated = true
+			break
+		}
+		current := queue[0]
+		queue = queue[1:]
+		result.Explored++
+
+		actions := TurnActions(current.entries, svc)
+		if len(current.actions) >= budget.MaxDepth {
+			if len(actions) > 0 {
+				result.Truncated = true
+			}
+			continue
+		}
+
+		for _, action := range actions {
+			next, err := game.ApplyAction(current.entries, action.Type, action.Parameters, result.Player, svc, nil)
+			if err != nil {
+				return nil, err
+			}
+			state := core.ReadGameState(next, svc)
+			hash, err := Hash(state)
+			if err != nil {
+				return nil, err
+			}
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+
+			line := make([]Step, len(current.actions), len(current.actions)+1)
+			copy(line, current.actions)
+			line = append(line, action)
+
+			result.EndStates = append(result.EndStates, EndState{Hash: hash, Actions: line, State: state, Entries: next})
+			queue = append(queue, node{entries: next, actions: line})
+		}
+	}
+
+	return result, nil
+}
+
+// TurnActions returns the distinct valid actions other than passing
+func TurnActions(entries []parser.LogEntry, svc *services.LensServices) []Step {
+	var steps []Step
+	tried := make(map[string]bool)
+	for _, action := range core.AvailableActions(entries, svc) {
+		if !action.Valid || action.Type == "pass" {
+			continue
+		}
+		// Copies of the same card in hand offer the same action
+		key, _ := json.Marshal([]interface{}{action.Type, action.Parameters})
+		if tried[string(key)] {
+			continue
+		}
+		tried[string(key)] = true
+		steps = append(steps, Step{Type: action.Type, Description: action.Description, Parameters: action.Parameters})
+	}
+	return steps
+}
+
+// Hash returns the canonical hash of a position. Instance IDs are left out,
+// as they depend on the order cards were played in rather than the position.
+func Hash(state *core.GameState) (string, error) {
+	canonical := *state
+	for i := range canonical.Players {
+		inPlay := make([]core.StateCard, len(state.Players[i].InPlay))
+		for j, card := range state.Players[i].InPlay {
+			card.InstanceID = ""
+			inPlay[j] = card
+		}
+		canonical.Players[i].InPlay = inPlay
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode state: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
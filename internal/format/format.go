@@ -0,0 +1,121 @@
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Default deckbuilding limits used when a format definition leaves them unset
+const (
+	DefaultMinDeckSize  = 60
+	DefaultMaxCopies    = 4
+	DefaultMaxInkColors = 2
+)
+
+// DefaultFormatID is the format assigned to decks that don't specify one
+const DefaultFormatID = "infinity"
+
+var validID = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Format describes which cards are legal and how decks must be built
+type Format struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	AllowedSets  []int          `json:"allowedSets,omitempty"` // Set numbers; empty allows every set
+	Banned       []string       `json:"banned,omitempty"`      // Full card names
+	Restricted   map[string]int `json:"restricted,omitempty"`  // Full card name -> max copies
+	MinDeckSize  int            `json:"minDeckSize"`
+	MaxDeckSize  int            `json:"maxDeckSize,omitempty"` // 0 means no maximum
+	MaxCopies    int            `json:"maxCopies"`
+	MaxInkColors int            `json:"maxInkColors"`
+}
+
+// Normalize fills in default limits and validates the definition
+func (f *Format) Normalize() error {
+	f.ID = strings.TrimSpace(f.ID)
+	if !validID.MatchString(f.ID) {
+		return fmt.Errorf("invalid format ID %q: use lowercase letters, digits, '-' or '_'", f.ID)
+	}
+	if f.Name == "" {
+		f.Name = f.ID
+	}
+	if f.MinDeckSize == 0 {
+		f.MinDeckSize = DefaultMinDeckSize
+	}
+	if f.MaxCopies == 0 {
+		f.MaxCopies = DefaultMaxCopies
+	}
+	if f.MaxInkColors == 0 {
+		f.MaxInkColors = DefaultMaxInkColors
+	}
+	if f.MinDeckSize < 0 || f.MaxCopies < 0 || f.MaxInkColors < 0 || f.MaxDeckSize < 0 {
+		return fmt.Errorf("format %s: limits must not be negative", f.ID)
+	}
+	if f.MaxDeckSize > 0 && f.MaxDeckSize < f.MinDeckSize {
+		return fmt.Errorf("format %s: maxDeckSize %d is below minDeckSize %d", f.ID, f.MaxDeckSize, f.MinDeckSize)
+	}
+	for name, limit := range f.Restricted {
+		if limit < 0 {
+			return fmt.Errorf("format %s: restricted limit for %s must not be negative", f.ID, name)
+		}
+	}
+	return nil
+}
+
+// AllowsSet returns true if cards from the given set are legal in this format
+func (f *Format) AllowsSet(setNum int) bool {
+	if len(f.AllowedSets) == 0 {
+		return true
+	}
+	for _, allowed := range f.AllowedSets {
+		if allowed == setNum {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBanned returns true if the card with the given full name is banned
+func (f *Format) IsBanned(name string) bool {
+	for _, banned := range f.Banned {
+		if strings.EqualFold(banned, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyLimit returns how many copies of the card with the given full name a deck may contain
+func (f *Format) CopyLimit(name string) int {
+	for restricted, limit := range f.Restricted {
+		if strings.EqualFold(restricted, name) {
+			return limit
+		}
+	}
+	return f.MaxCopies
+}
+
+// builtinFormats returns the formats available without any definition files
+func builtinFormats() []*Format {
+	return []*Format{
+		{
+			ID:           "core",
+			Name:         "Core",
+			Description:  "Rotating format using the most recent sets",
+			AllowedSets:  []int{5, 6, 7, 8},
+			MinDeckSize:  DefaultMinDeckSize,
+			MaxCopies:    DefaultMaxCopies,
+			MaxInkColors: DefaultMaxInkColors,
+		},
+		{
+			ID:           "infinity",
+			Name:         "Infinity",
+			Description:  "Every set is legal",
+			MinDeckSize:  DefaultMinDeckSize,
+			MaxCopies:    DefaultMaxCopies,
+			MaxInkColors: DefaultMaxInkColors,
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir is where format definition files are read from and written to
+const DefaultDir = "data/formats"
+
+var (
+	formats = make(map[string]*Format)
+	mutex   sync.RWMutex
+)
+
+func init() {
+	for _, f := range builtinFormats() {
+		formats[f.ID] = f
+	}
+}
+
+// Get returns the format with the given ID
+func Get(id string) (*Format, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	f, exists := formats[id]
+	return f, exists
+}
+
+// List returns all registered formats sorted by ID
+func List() []*Format {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	result := make([]*Format, 0, len(formats))
+	for _, f := range formats {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Register validates a format and adds it, replacing any format with the same ID
+func Register(f *Format) error {
+	if err := f.Normalize(); err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	formats[f.ID] = f
+	return nil
+}
+
+// LoadFile reads and registers a single JSON or YAML format definition
+func LoadFile(path string) (*Format, error) {
+	f, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	formats[f.ID] = f
+	return f, nil
+}
+
+// readFile decodes and validates a format definition. YAML files use the same
+// field names as JSON ones.
+func readFile(path string) (*Format, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read format file: %w", err)
+	}
+
+	if isYAML(path) {
+		var fields map[string]interface{}
+		if err := yaml.Unmarshal(content, &fields); err != nil {
+			return nil, fmt.Errorf("failed to decode format file %s: %w", path, err)
+		}
+		if content, err = json.Marshal(fields); err != nil {
+			return nil, fmt.Errorf("failed to decode format file %s: %w", path, err)
+		}
+	}
+
+	var f Format
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode format file %s: %w", path, err)
+	}
+
+	if err := f.Normalize(); err != nil {
+		return nil, fmt.Errorf("invalid format file %s: %w", path, err)
+	}
+
+	return &f, nil
+}
+
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// LoadDir replaces the registered formats with the built-in ones and every
+// JSON or YAML format definition in a directory. If any file fails to load
+// the registry is left unchanged. A missing directory is not an error; only
+// the built-in formats are available then.
+func LoadDir(dir string) ([]*Format, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read format directory: %w", err)
+	}
+
+	registry := make(map[string]*Format)
+	for _, f := range builtinFormats() {
+		registry[f.ID] = f
+	}
+
+	var loaded []*Format
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (filepath.Ext(name) != ".json" && !isYAML(name)) {
+			continue
+		}
+
+		f, err := readFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		registry[f.ID] = f
+		loaded = append(loaded, f)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	formats = registry
+	return loaded, nil
+}
+
+// Save registers a format and writes its definition to the given directory
+func Save(f *Format, dir string) error {
+	if err := Register(f); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create format directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal format: %w", err)
+	}
+
+	path := filepath.Join(dir, f.ID+".json")
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write format file: %w", err)
+	}
+
+	return nil
+}
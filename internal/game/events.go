@@ -0,0 +1,62 @@
+package game
+
+import (
+	"log"
+
+	"quards/internal/events"
+	"quards/internal/lens"
+	"quards/internal/parser"
+)
+
+// publishUpdate notifies subscribers of a game that its log changed. Entries
+// from fromStep onward are sent as new; a reset sends only the new state.
+func publishUpdate(gameData *Game, logContent string, fromStep int, eventType string, processor *lens.Processor) {
+	if !events.Default.HasSubscribers(gameData.ID) {
+		return
+	}
+
+	entries, err := parser.ParseLogContent(logContent)
+	if err != nil {
+		log.Printf("game %d: failed to parse log for subscribers: %v", gameData.ID, err)
+		return
+	}
+
+	if processor == nil {
+		if processor, err = LensProcessor(gameData); err != nil {
+			log.Printf("game %d: %v", gameData.ID, err)
+			return
+		}
+	}
+	state, err := processor.Lens("composite", entries)
+	if err != nil {
+		log.Printf("game %d: failed to compute state for subscribers: %v", gameData.ID, err)
+		return
+	}
+
+	event := events.GameEvent{
+		Type:    eventType,
+		GameID:  gameData.ID,
+		Step:    len(entries),
+		Entries: []events.Entry{},
+		State:   state,
+	}
+	if eventType == events.TypeAppend && fromStep < len(entries) {
+		event.Entries = EventEntries(entries[fromStep:])
+	}
+
+	events.Default.Publish(event)
+}
+
+// EventEntries converts log entries to the form delivered to subscribers
+func EventEntries(entries []parser.LogEntry) []events.Entry {
+	result := make([]events.Entry, len(entries))
+	for i, entry := range entries {
+		result[i] = events.Entry{
+			Step:       entry.Step,
+			Event:      string(entry.Event),
+			Player:     entry.GetPlayer(),
+			Parameters: entry.Parameters,
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"quards/internal/database"
+	"quards/internal/parser"
+)
+
+// ErrInvalidForkStep is returned when a fork step is outside the parent's log
+var ErrInvalidForkStep = errors.New("invalid fork step")
+
+// GameTreeNode is a game with the games forked from it
+type GameTreeNode struct {
+	ID           int             `json:"id"`
+	ParentGameID *int            `json:"parentGameId"`
+	ForkStep     *int            `json:"forkStep"`
+	Player1Deck  string          `json:"player1Deck"`
+	Player2Deck  string          `json:"player2Deck"`
+	Status       string          `json:"status"`
+	Winner       *int            `json:"winner"`
+	Turns        int             `json:"turns"`
+	Created      time.Time       `json:"created"`
+	Forks        []*GameTreeNode `json:"forks"`
+}
+
+// ForkGame creates a new game from the first step log entries of another,
+// pinned to the same deck versions and card overlays. The parent is left
+// untouched and recorded as the fork's lineage.
+func ForkGame(parentID, step int) (*Game, error) {
+	parent, err := LoadGame(parentID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parser.ParseLogContent(parent.LogContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse game log: %w", err)
+	}
+	if step < 1 || step > len(entries) {
+		return nil, fmt.Errorf("%w: step must be between 1 and %d", ErrInvalidForkStep, len(entries))
+	}
+
+	logContent, _ := splitLog(parent.LogContent, step)
+
+	db := database.GetDB()
+	var gameID int
+	err = db.QueryRow(`
+		INSERT INTO games (player1_deck, player2_deck, player1_deck_id, player2_deck_id,
+		                   player1_deck_version_id, player2_deck_version_id, card_overlay_id, card_overlay_ids,
+		                   seed, log_content, status, parent_game_id, fork_step)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'created', $11, $12)
+		RETURNING id`,
+		parent.Player1Deck, parent.Player2Deck, parent.Player1DeckID, parent.Player2DeckID,
+		parent.Player1DeckVersionID, parent.Player2DeckVersionID, parent.CardOverlayID,
+		overlayIDArray(parent.CardOverlayIDs), parent.Seed, logContent, parent.ID, step).Scan(&gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fork: %w", err)
+	}
+
+	return LoadGame(gameID)
+}
+
+// GameTree returns a game with every game forked from it, directly or from
+// one of its forks, ordered by fork step and creation
+func GameTree(gameID int) (*GameTreeNode, error) {
+	db := database.GetDB()
+
+	rows, err := db.Query(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM games WHERE id = $1
+			UNION ALL
+			SELECT g.id FROM games g JOIN tree t ON g.parent_game_id = t.id
+		)
+		SELECT g.id, g.parent_game_id, g.fork_step, g.player1_deck, g.player2_deck,
+		       g.status, g.winner, g.turns, g.created_at
+		FROM games g JOIN tree t ON g.id = t.id
+		ORDER BY g.fork_step NULLS FIRST, g.created_at, g.id`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query game tree: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make(map[int]*GameTreeNode)
+	var order []*GameTreeNode
+	for rows.Next() {
+		node := &GameTreeNode{Forks: []*GameTreeNode{}}
+		err := rows.Scan(&node.ID, &node.ParentGameID, &node.ForkStep, &node.Player1Deck, &node.Player2Deck,
+			&node.Status, &node.Winner, &node.Turns, &node.Created)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+		nodes[node.ID] = node
+		order = append(order, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	root, exists := nodes[gameID]
+	if !exists {
+		return nil, fmt.Errorf("game not found: %d", gameID)
+	}
+	for _, node := range order {
+		if node.ID == gameID || node.ParentGameID == nil {
+			continue
+		}
+		if parent, exists := nodes[*node.ParentGameID]; exists {
+			parent.Forks = append(parent.Forks, node)
+		}
+	}
+	return root, nil
+}
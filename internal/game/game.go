@@ -4,20 +4,32 @@ import (
 	"database/sql"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"quards/internal/database"
 	"quards/internal/deck"
+	"quards/internal/events"
 	"quards/internal/lens"
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/overlay"
 	"quards/internal/parser"
 )
 
 // writeEventLog creates a properly formatted event-sourcing log entry
 func writeEventLog(event string, params map[string]interface{}) string {
 	line := event
-	for key, value := range params {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := params[key]
 		// Quote string values to handle spaces properly
 		if str, ok := value.(string); ok {
 			line += fmt.Sprintf(" %s=%q", key, str)
@@ -43,6 +55,8 @@ func mapActionToEventName(action string) string {
 		return "TurnPassed"
 	case "turn_start":
 		return "TurnStarted"
+	case "mulligan":
+		return "HandMulliganed"
 	default:
 		return action // Fallback
 	}
@@ -50,28 +64,41 @@ func mapActionToEventName(action string) string {
 
 // Game represents a game instance
 type Game struct {
-	ID          int       `json:"id"`
-	Player1Deck string    `json:"player1Deck"`
-	Player2Deck string    `json:"player2Deck"`
-	Seed        *int      `json:"seed"`
-	LogContent  string    `json:"logContent"`
-	Status      string    `json:"status"`
-	Winner      *int      `json:"winner"`
-	Turns       int       `json:"turns"`
-	Created     time.Time `json:"created"`
-	Modified    time.Time `json:"modified"`
+	ID                   int       `json:"id"`
+	Player1Deck          string    `json:"player1Deck"`
+	Player2Deck          string    `json:"player2Deck"`          // Deck names as recorded in the game log
+	Player1DeckID        *int      `json:"player1DeckId"`
+	Player2DeckID        *int      `json:"player2DeckId"`
+	Player1DeckVersionID *int      `json:"player1DeckVersionId"` // Deck version the game was created with
+	Player2DeckVersionID *int      `json:"player2DeckVersionId"`
+	CardOverlayID        *int      `json:"cardOverlayId"` // Card overlay version requested for the game
+	CardOverlayIDs       []int     `json:"cardOverlayIds"` // Overlay versions the game is played with, in order
+	ParentGameID         *int      `json:"parentGameId"`  // Game this one was forked from
+	ForkStep             *int      `json:"forkStep"`      // Log entries copied from the parent
+	Seed                 *int      `json:"seed"`
+	LogContent           string    `json:"logContent"`
+	Status               string    `json:"status"`
+	Winner               *int      `json:"winner"`
+	Turns                int       `json:"turns"`
+	Created              time.Time `json:"created"`
+	Modified             time.Time `json:"modified"`
+	Spectators           SpectatorSettings `json:"spectators"`
+	Seats                []Seat    `json:"seats,omitempty"`
 }
 
 // GameList represents a simplified game list for API responses
 type GameList struct {
-	ID          int       `json:"id"`
-	Player1Deck string    `json:"player1Deck"`
-	Player2Deck string    `json:"player2Deck"`
-	Seed        *int      `json:"seed"`
-	Status      string    `json:"status"`
-	Winner      *int      `json:"winner"`
-	Turns       int       `json:"turns"`
-	Created     time.Time `json:"created"`
+	ID            int       `json:"id"`
+	Player1Deck   string    `json:"player1Deck"`
+	Player2Deck   string    `json:"player2Deck"`
+	Player1DeckID *int      `json:"player1DeckId"`
+	Player2DeckID *int      `json:"player2DeckId"`
+	ParentGameID  *int      `json:"parentGameId"`
+	Seed          *int      `json:"seed"`
+	Status        string    `json:"status"`
+	Winner        *int      `json:"winner"`
+	Turns         int       `json:"turns"`
+	Created       time.Time `json:"created"`
 }
 
 // CreateGameRequest represents the request to create a new game
@@ -79,45 +106,83 @@ type CreateGameRequest struct {
 	Player1Deck string `json:"player1Deck"` // Can be deck name or ID as string
 	Player2Deck string `json:"player2Deck"` // Can be deck name or ID as string  
 	Seed        *int   `json:"seed"`
-	LogContent  string `json:"logContent,omitempty"` // For uploaded games
+	LogContent  string `json:"logContent,omitempty"`  // For uploaded games
+	CardOverlay string `json:"cardOverlay,omitempty"` // Overlay ID, or name for its latest version
+	Seat        int    `json:"seat,omitempty"`        // Seat the creator takes, defaults to 1
+	OpenSeat    bool   `json:"openSeat,omitempty"`    // Let anyone take the other seat without an invite
+	Bot         string `json:"bot,omitempty"`         // Kind of bot to take the other seat
+	Spectators  SpectatorSettings `json:"spectators"`
+	CreatorID   int    `json:"-"`                     // Signed-in user creating the game, set by the server
 }
 
-// resolveDeckName resolves a deck identifier (name or ID as string) to a deck name
-func resolveDeckName(deckIdentifier string) (string, error) {
+// resolveDeck resolves a deck identifier (name or ID as string) to a deck
+func resolveDeck(deckIdentifier string) (*deck.Deck, error) {
 	// Try to parse as integer ID first
 	if id, err := strconv.Atoi(deckIdentifier); err == nil {
-		// It's an ID, load deck by ID and return name
 		deckData, err := deck.LoadDeckByID(id)
 		if err != nil {
-			return "", fmt.Errorf("failed to load deck by ID %d: %w", id, err)
+			return nil, fmt.Errorf("failed to load deck by ID %d: %w", id, err)
 		}
-		return deckData.Name, nil
+		return deckData, nil
 	}
 	
 	// It's a name, validate it exists
-	_, err := deck.LoadDeck(deckIdentifier)
+	deckData, err := deck.LoadDeck(deckIdentifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deck by name %s: %w", deckIdentifier, err)
+	}
+	
+	return deckData, nil
+}
+
+// ResolveDeckVersion resolves a deck identifier to the deck's latest saved version
+func ResolveDeckVersion(deckIdentifier string) (*deck.DeckVersion, error) {
+	deckData, err := resolveDeck(deckIdentifier)
 	if err != nil {
-		return "", fmt.Errorf("failed to load deck by name %s: %w", deckIdentifier, err)
+		return nil, err
 	}
 	
-	return deckIdentifier, nil
+	versionID, err := deck.LatestVersionID(deckData.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	return deck.LoadVersionByID(versionID)
 }
 
-// CreateGame creates a new game and generates initial log
+// CreateGame creates a new game pinned to the current versions of both decks and generates the initial log
 func CreateGame(req *CreateGameRequest) (*Game, error) {
 	db := database.GetDB()
 
-	// Resolve deck identifiers to names
-	player1DeckName, err := resolveDeckName(req.Player1Deck)
+	// Resolve deck identifiers to the deck versions this game will use
+	player1Deck, err := ResolveDeckVersion(req.Player1Deck)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve player 1 deck: %w", err)
 	}
 	
-	player2DeckName, err := resolveDeckName(req.Player2Deck)
+	player2Deck, err := ResolveDeckVersion(req.Player2Deck)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve player 2 deck: %w", err)
 	}
 
+	// Pin the overlay versions so replays use the same cards
+	var cardOverlayID *int
+	if req.CardOverlay != "" {
+		cardOverlay, err := overlay.Resolve(req.CardOverlay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve card overlay: %w", err)
+		}
+		cardOverlayID = &cardOverlay.ID
+	}
+	cardOverlayIDs, err := gameOverlayIDs(player1Deck, player2Deck, cardOverlayID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.Spectators.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Generate seed if not provided
 	seed := req.Seed
 	if seed == nil {
@@ -130,16 +195,22 @@ func CreateGame(req *CreateGameRequest) (*Game, error) {
 	if req.LogContent != "" {
 		logContent = req.LogContent
 	} else {
-		logContent = generateInitialLog(player1DeckName, player2DeckName, *seed)
+		logContent = InitialLog(player1Deck, player2Deck, *seed)
 	}
 
 	// Insert game into database
 	var gameID int
 	err = db.QueryRow(`
-		INSERT INTO games (player1_deck, player2_deck, seed, log_content, status)
-		VALUES ($1, $2, $3, $4, 'created')
+		INSERT INTO games (player1_deck, player2_deck, player1_deck_id, player2_deck_id,
+		                   player1_deck_version_id, player2_deck_version_id, card_overlay_id, card_overlay_ids,
+		                   seed, log_content, status,
+		                   spectator_delay_turns, spectator_delay_minutes, caster_view, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'created', $11, $12, $13, $14)
 		RETURNING id`,
-		player1DeckName, player2DeckName, seed, logContent).Scan(&gameID)
+		player1Deck.Name, player2Deck.Name, player1Deck.DeckID, player2Deck.DeckID,
+		player1Deck.ID, player2Deck.ID, cardOverlayID, overlayIDArray(cardOverlayIDs), seed, logContent,
+		req.Spectators.DelayTurns, req.Spectators.DelayMinutes, req.Spectators.CasterView,
+		storedID(req.CreatorID)).Scan(&gameID)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create game: %w", err)
@@ -149,18 +220,62 @@ func CreateGame(req *CreateGameRequest) (*Game, error) {
 	return LoadGame(gameID)
 }
 
+// RecordGame stores a game played outside the server, such as a simulated
+// one, with its result. Decks that were not loaded from the database are
+// stored by name only. Games without a winner are left in progress.
+func RecordGame(player1Deck, player2Deck *deck.DeckVersion, seed int, logContent string, winner, turns int) (int, error) {
+	db := database.GetDB()
+
+	status := "completed"
+	var winnerValue *int
+	if winner != 0 {
+		winnerValue = &winner
+	} else {
+		status = "in_progress"
+	}
+
+	var gameID int
+	err := db.QueryRow(`
+		INSERT INTO games (player1_deck, player2_deck, player1_deck_id, player2_deck_id,
+		                   player1_deck_version_id, player2_deck_version_id,
+		                   seed, log_content, status, winner, turns)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		player1Deck.Name, player2Deck.Name, storedID(player1Deck.DeckID), storedID(player2Deck.DeckID),
+		storedID(player1Deck.ID), storedID(player2Deck.ID), seed, logContent, status, winnerValue, turns).Scan(&gameID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record game: %w", err)
+	}
+
+	return gameID, nil
+}
+
+// storedID returns a database ID, or nil for the zero ID of a deck that was
+// never saved
+func storedID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
 // LoadGame loads a game by ID
 func LoadGame(id int) (*Game, error) {
 	db := database.GetDB()
 
 	var game Game
+	var cardOverlayIDs pq.Int64Array
 	err := db.QueryRow(`
-		SELECT id, player1_deck, player2_deck, seed, log_content, 
-		       status, winner, turns, created_at, modified_at
+		SELECT id, player1_deck, player2_deck, player1_deck_id, player2_deck_id,
+		       player1_deck_version_id, player2_deck_version_id, card_overlay_id, card_overlay_ids,
+		       seed, log_content, status, winner, turns, created_at, modified_at,
+		       spectator_delay_turns, spectator_delay_minutes, caster_view, parent_game_id, fork_step
 		FROM games WHERE id = $1`, id).Scan(
-		&game.ID, &game.Player1Deck, &game.Player2Deck,
-		&game.Seed, &game.LogContent, &game.Status, &game.Winner,
-		&game.Turns, &game.Created, &game.Modified)
+		&game.ID, &game.Player1Deck, &game.Player2Deck, &game.Player1DeckID, &game.Player2DeckID,
+		&game.Player1DeckVersionID, &game.Player2DeckVersionID, &game.CardOverlayID, &cardOverlayIDs, &game.Seed, &game.LogContent, &game.Status, &game.Winner,
+		&game.Turns, &game.Created, &game.Modified,
+		&game.Spectators.DelayTurns, &game.Spectators.DelayMinutes, &game.Spectators.CasterView,
+		&game.ParentGameID, &game.ForkStep)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -168,6 +283,10 @@ func LoadGame(id int) (*Game, error) {
 		}
 		return nil, fmt.Errorf("failed to load game: %w", err)
 	}
+	game.CardOverlayIDs = make([]int, len(cardOverlayIDs))
+	for i, id := range cardOverlayIDs {
+		game.CardOverlayIDs[i] = int(id)
+	}
 
 	return &game, nil
 }
@@ -177,13 +296,18 @@ func LoadGameByName(name string) (*Game, error) {
 	db := database.GetDB()
 
 	var game Game
+	var cardOverlayIDs pq.Int64Array
 	err := db.QueryRow(`
-		SELECT id, player1_deck, player2_deck, seed, log_content,
-		       status, winner, turns, created_at, modified_at
+		SELECT id, player1_deck, player2_deck, player1_deck_id, player2_deck_id,
+		       player1_deck_version_id, player2_deck_version_id, card_overlay_id, card_overlay_ids,
+		       seed, log_content, status, winner, turns, created_at, modified_at,
+		       spectator_delay_turns, spectator_delay_minutes, caster_view, parent_game_id, fork_step
 		FROM games WHERE name = $1`, name).Scan(
-		&game.ID, &game.Player1Deck, &game.Player2Deck,
-		&game.Seed, &game.LogContent, &game.Status, &game.Winner,
-		&game.Turns, &game.Created, &game.Modified)
+		&game.ID, &game.Player1Deck, &game.Player2Deck, &game.Player1DeckID, &game.Player2DeckID,
+		&game.Player1DeckVersionID, &game.Player2DeckVersionID, &game.CardOverlayID, &cardOverlayIDs, &game.Seed, &game.LogContent, &game.Status, &game.Winner,
+		&game.Turns, &game.Created, &game.Modified,
+		&game.Spectators.DelayTurns, &game.Spectators.DelayMinutes, &game.Spectators.CasterView,
+		&game.ParentGameID, &game.ForkStep)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -191,21 +315,84 @@ func LoadGameByName(name string) (*Game, error) {
 		}
 		return nil, fmt.Errorf("failed to load game: %w", err)
 	}
+	game.CardOverlayIDs = make([]int, len(cardOverlayIDs))
+	for i, id := range cardOverlayIDs {
+		game.CardOverlayIDs[i] = int(id)
+	}
 
 	return &game, nil
 }
 
-// ListGames returns a list of all games, optionally filtered by deck
+// gameOverlayIDs returns the overlay versions a new game is played with: the
+// errata of its decks' formats, then the requested overlay, the same cards
+// the decks were checked against
+func gameOverlayIDs(player1Deck, player2Deck *deck.DeckVersion, cardOverlayID *int) ([]int, error) {
+	ids := []int{}
+	seen := make(map[int]bool)
+	for _, formatID := range []string{player1Deck.Format, player2Deck.Format} {
+		formatIDs, err := overlay.FormatOverlayIDs(formatID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load format errata: %w", err)
+		}
+		for _, id := range formatIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if cardOverlayID != nil && !seen[*cardOverlayID] {
+		ids = append(ids, *cardOverlayID)
+	}
+	return ids, nil
+}
+
+// overlayIDArray converts overlay IDs for an INTEGER[] column
+func overlayIDArray(ids []int) pq.Int64Array {
+	array := make(pq.Int64Array, len(ids))
+	for i, id := range ids {
+		array[i] = int64(id)
+	}
+	return array
+}
+
+// LensProcessor returns a lens processor that sees the game's cards, with the
+// game's card overlays applied in order
+func LensProcessor(gameData *Game) (*lens.Processor, error) {
+	processor := lens.New()
+	if len(gameData.CardOverlayIDs) == 0 {
+		return processor, nil
+	}
+
+	cardDB, err := overlay.StackCardDB(gameData.CardOverlayIDs, processor.Services().CardDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load card overlays: %w", err)
+	}
+
+	return lens.NewWithServices(&services.LensServices{
+		CardDB: cardDB,
+		Cache:  processor.Services().Cache,
+	}), nil
+}
+
+// ListGames returns a list of all games, optionally filtered by deck ID or name
 func ListGames(deckFilter string) ([]GameList, error) {
 	db := database.GetDB()
 
+	// Seeds determine every library's order, so they stay hidden while seated players are still playing
 	query := `
-		SELECT id, player1_deck, player2_deck, seed, status, 
-		       winner, turns, created_at
+		SELECT id, player1_deck, player2_deck, player1_deck_id, player2_deck_id, parent_game_id,
+		       CASE WHEN status <> 'completed' AND EXISTS (
+		           SELECT 1 FROM game_seats s WHERE s.game_id = games.id AND s.user_id IS NOT NULL
+		       ) THEN NULL ELSE seed END,
+		       status, winner, turns, created_at
 		FROM games`
 	args := []interface{}{}
 
-	if deckFilter != "" {
+	if deckID, err := strconv.Atoi(deckFilter); err == nil {
+		query += " WHERE player1_deck_id = $1 OR player2_deck_id = $1"
+		args = append(args, deckID)
+	} else if deckFilter != "" {
 		query += " WHERE player1_deck = $1 OR player2_deck = $1"
 		args = append(args, deckFilter)
 	}
@@ -222,7 +409,7 @@ func ListGames(deckFilter string) ([]GameList, error) {
 	for rows.Next() {
 		var game GameList
 		err := rows.Scan(&game.ID, &game.Player1Deck, &game.Player2Deck,
-			&game.Seed, &game.Status, &game.Winner, &game.Turns, &game.Created)
+			&game.Player1DeckID, &game.Player2DeckID, &game.ParentGameID, &game.Seed, &game.Status, &game.Winner, &game.Turns, &game.Created)
 		if err != nil {
 			continue // Skip invalid rows
 		}
@@ -253,24 +440,15 @@ func DeleteGame(id int) error {
 	return nil
 }
 
-// generateInitialLog creates a basic game log with setup actions in JSON Lines format
-func generateInitialLog(player1DeckName, player2DeckName string, seed int) string {
+// InitialLog creates a basic game log with setup actions in JSON Lines format
+func InitialLog(player1Deck, player2Deck *deck.DeckVersion, seed int) string {
 	var entries []string
-
-	// Load deck contents
-	player1DeckData, err := deck.LoadDeck(player1DeckName)
-	if err != nil {
-		return "Failed to load player one's deck: " + err.Error()
-	}
-
-	player2DeckData, err := deck.LoadDeck(player2DeckName)
-	if err != nil {
-		return "Failed to load player two's deck: " + err.Error()
-	}
+	player1DeckName := player1Deck.Name
+	player2DeckName := player2Deck.Name
 
 	// Create card lists from deck data
-	player1Cards := expandDeckToCards(player1DeckData.Cards)
-	player2Cards := expandDeckToCards(player2DeckData.Cards)
+	player1Cards := expandDeckToCards(player1Deck.Cards)
+	player2Cards := expandDeckToCards(player2Deck.Cards)
 
 	// Shuffle using the provided seed
 	rng := rand.New(rand.NewSource(int64(seed)))
@@ -312,196 +490,80 @@ func generateInitialLog(player1DeckName, player2DeckName string, seed int) strin
 
 	return strings.Join(entries, "\n") + "\n"
 }
+
+// AppendActionToGame appends an action to a game log by name without
+// checking who submitted it
 func AppendActionToGame(gameName, actionType string, parameters map[string]interface{}) error {
-	// Load the game
 	gameData, err := LoadGameByName(gameName)
 	if err != nil {
 		return fmt.Errorf("failed to load game: %w", err)
 	}
-
-	// Parse current log to get the current state
-	entries, err := parser.ParseLogContent(gameData.LogContent)
-	if err != nil {
-		return fmt.Errorf("failed to parse game log: %w", err)
-	}
-
-	// Use lens to get current game state instead of manual parsing
-	processor := lens.New()
-	gameStateData, err := processor.Lens("gameState", entries)
-	if err != nil {
-		return fmt.Errorf("failed to get game state: %w", err)
-	}
-	
-	gameState := gameStateData.(map[string]interface{})
-	currentPlayer := gameState["currentPlayer"].(int)
-	currentTurn := gameState["currentTurn"].(int)
-
-	// Create the new log entry in event-sourcing format
-	eventName := mapActionToEventName(actionType)
-	// Add player parameter if not already present
-	if currentPlayer > 0 {
-		parameters["player"] = currentPlayer
-	}
-	newLogLine := writeEventLog(eventName, parameters)
-
-	// Start building the updated log content
-	updatedLogContent := gameData.LogContent + newLogLine + "\n"
-
-	// If this is a pass action, we need to add automatic follow-up actions
-	if actionType == "pass" {
-		// Get the next player (who will start their turn)
-		nextPlayer := currentPlayer
-		if currentPlayer == 1 {
-			nextPlayer = 2
-		} else {
-			nextPlayer = 1
-		}
-
-		// Determine the next turn number
-		nextTurn := currentTurn + 1
-
-		// Player 1 doesn't draw on turn 1 (their very first turn), but draws on all other turns
-		// Player 2 always draws at the start of their turns
-		shouldDraw := !(nextTurn == 1 && nextPlayer == 1)
-
-		// Add draw_card action if the player should draw
-		if shouldDraw {
-			// Get next card from deck for the player
-			nextCard, err := getNextCardFromDeck(entries, gameData, nextPlayer)
-			if err == nil && nextCard != "" {
-				drawLogLine := writeEventLog("CardDrawn", map[string]interface{}{
-					"card_id": nextCard,
-					"player":  nextPlayer,
-				})
-				updatedLogContent += drawLogLine + "\n"
-			}
-		}
-
-		// Add turn_start action for the next player
-		turnStartLogLine := writeEventLog("TurnStarted", map[string]interface{}{
-			"player": nextPlayer,
-			"turn":   nextTurn,
-		})
-		updatedLogContent += turnStartLogLine + "\n"
-	}
-
-	// Update the game in the database
-	db := database.GetDB()
-	// TODO: Consider using a different storage mechanism for this (appending instead of replacing))
-	_, err = db.Exec("UPDATE games SET log_content = $1, modified_at = NOW() WHERE name = $2",
-		updatedLogContent, gameName)
-	if err != nil {
-		return fmt.Errorf("failed to update game log: %w", err)
-	}
-
-	return nil
+	return appendActionByID(strconv.Itoa(gameData.ID), actionType, parameters, nil)
 }
 
-// getNextCardFromDeck determines what card a player should draw next from their deck
-func getNextCardFromDeck(entries []parser.LogEntry, gameData *Game, player int) (string, error) {
-	// First, find the game_start entry to get deck names and seed
+// gameDecks returns the source of the cards the game's players draw from
+func GameDecks(gameData *Game, entries []parser.LogEntry) DeckSource {
 	var player1DeckName, player2DeckName string
-	var seed int
-
 	for _, entry := range entries {
-		if string(entry.Event) == "GameStarted" && entry.Parameters != nil {
-			if deck1, ok := entry.Parameters["p1_deck"]; ok {
-				player1DeckName = deck1
-				fmt.Printf("DEBUG DECK: p1_deck='%s'\n", player1DeckName)
-			}
-			if deck2, ok := entry.Parameters["p2_deck"]; ok {
-				player2DeckName = deck2
-				fmt.Printf("DEBUG DECK: p2_deck='%s'\n", player2DeckName)
-			}
-			seed = entry.GetInt("seed")
+		if entry.Event == parser.GameStarted {
+			player1DeckName = entry.GetCard("p1_deck")
+			player2DeckName = entry.GetCard("p2_deck")
 		}
 	}
 
-	if player1DeckName == "" || player2DeckName == "" {
-		return "", fmt.Errorf("could not find deck names in game log")
-	}
-
-	// Load the appropriate deck
-	var deckName string
-	if player == 1 {
-		deckName = player1DeckName
-	} else {
-		deckName = player2DeckName
+	return func(player int) (map[string]int, error) {
+		if player1DeckName == "" || player2DeckName == "" {
+			return nil, fmt.Errorf("could not find deck names in game log")
+		}
+		// Load the deck version the game was created with, falling back to the
+		// deck's current contents for games created before versions were pinned
+		return loadGameDeckCards(gameData, player, player1DeckName, player2DeckName)
 	}
+}
 
-	deckData, err := deck.LoadDeck(deckName)
-	if err != nil {
-		return "", fmt.Errorf("failed to load deck %s: %w", deckName, err)
+// loadGameDeckCards returns the card list a player's deck had when the game was created
+func loadGameDeckCards(gameData *Game, player int, player1DeckName, player2DeckName string) (map[string]int, error) {
+	versionID, deckID, deckName := gameData.Player1DeckVersionID, gameData.Player1DeckID, player1DeckName
+	if player == 2 {
+		versionID, deckID, deckName = gameData.Player2DeckVersionID, gameData.Player2DeckID, player2DeckName
 	}
 
-	// Expand deck to card list and shuffle with the same seed
-	cards := expandDeckToCards(deckData.Cards)
-	rng := rand.New(rand.NewSource(int64(seed)))
-	rng.Shuffle(len(cards), func(i, j int) {
-		cards[i], cards[j] = cards[j], cards[i]
-	})
-
-	// Track cards already drawn/used by this player
-	var cardsUsed []string
-
-	// Process all entries to track card movements
-	for _, entry := range entries {
-		if entry.GetPlayer() == player {
-			switch string(entry.Event) {
-			case "CardDrawn", "CardInked", "CardPlayed":
-				if entry.Parameters != nil {
-					cardID := entry.GetCard("card_id")
-					if cardID != "" {
-						cardsUsed = append(cardsUsed, cardID)
-					}
-				}
-			}
-		} else if string(entry.Event) == "OpeningHandsDrawn" && entry.Parameters != nil {
-			// Handle opening hands
-			var playerCards []interface{}
-			if player == 1 {
-				cardStrings := entry.GetStringSlice("p1")
-				playerCards = make([]interface{}, len(cardStrings))
-				for i, card := range cardStrings {
-					playerCards[i] = card
-				}
-			} else {
-				cardStrings := entry.GetStringSlice("p2")
-				playerCards = make([]interface{}, len(cardStrings))
-				for i, card := range cardStrings {
-					playerCards[i] = card
-				}
-			}
-
-			for _, cardInterface := range playerCards {
-				if cardID, ok := cardInterface.(string); ok {
-					cardsUsed = append(cardsUsed, cardID)
-				}
-			}
+	if versionID != nil {
+		version, err := deck.LoadVersionByID(*versionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load deck version %d: %w", *versionID, err)
 		}
+		return version.Cards, nil
 	}
 
-	// Find the next card in the shuffled deck that hasn't been used
-	for _, cardID := range cards {
-		used := false
-		for _, usedCard := range cardsUsed {
-			if cardID == usedCard {
-				used = true
-				break
-			}
-		}
-		if !used {
-			return cardID, nil
+	// Prefer the deck ID so renamed decks still resolve
+	if deckID != nil {
+		deckData, err := deck.LoadDeckByID(*deckID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load deck %d: %w", *deckID, err)
 		}
+		return deckData.Cards, nil
 	}
 
-	return "", fmt.Errorf("no more cards available in deck for player %d", player)
+	deckData, err := deck.LoadDeck(deckName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deck %s: %w", deckName, err)
+	}
+	return deckData.Cards, nil
 }
 
-// expandDeckToCards converts a deck's card count map to a list of individual card IDs
+// expandDeckToCards converts a deck's card count map to a list of individual card IDs.
+// Card IDs are sorted first so that shuffling with the same seed always gives the same order.
 func expandDeckToCards(deckCards map[string]int) []string {
+	cardIDs := make([]string, 0, len(deckCards))
+	for cardID := range deckCards {
+		cardIDs = append(cardIDs, cardID)
+	}
+	sort.Strings(cardIDs)
+
 	var cards []string
-	for cardID, count := range deckCards {
+	for _, cardID := range cardIDs {
+		count := deckCards[cardID]
 		for i := 0; i < count; i++ {
 			cards = append(cards, cardID)
 		}
@@ -509,17 +571,6 @@ func expandDeckToCards(deckCards map[string]int) []string {
 	return cards
 }
 
-// TruncateGame truncates a game's log to the provided content
-func TruncateGame(gameName, newLogContent string) error {
-	db := database.GetDB()
-	_, err := db.Exec("UPDATE games SET log_content = $1, modified_at = NOW() WHERE name = $2",
-		newLogContent, gameName)
-	if err != nil {
-		return fmt.Errorf("failed to update game log: %w", err)
-	}
-	return nil
-}
-
 // LoadGameByID is an alias for LoadGame for consistency
 func LoadGameByID(gameID string) (*Game, error) {
 	id := 0
@@ -529,28 +580,96 @@ func LoadGameByID(gameID string) (*Game, error) {
 	return LoadGame(id)
 }
 
-// TruncateGameByID truncates a game log by ID
-func TruncateGameByID(gameID, newLogContent string) error {
+// playerParameter reads the player number a response action is taken for
+func playerParameter(parameters map[string]interface{}) (int, error) {
+	var player int
+	switch value := parameters["player"].(type) {
+	case float64:
+		player = int(value)
+	case int:
+		player = value
+	case string:
+		player, _ = strconv.Atoi(value)
+	}
+	if player != 1 && player != 2 {
+		return 0, fmt.Errorf("response actions require a player parameter of 1 or 2")
+	}
+	return player, nil
+}
+
+// AppendActionToGameByID appends an action to a game log by ID without
+// checking who submitted it
+func AppendActionToGameByID(gameID, actionType string, parameters map[string]interface{}) error {
+	return appendActionByID(gameID, actionType, parameters, nil)
+}
+
+// AppendActionAsUser appends an action submitted by a user. In games with
+// seated users, the user must hold the seat of the acting player: the active
+// player, or for response actions the player named in the parameters.
+func AppendActionAsUser(gameID string, userID int, actionType string, parameters map[string]interface{}) error {
+	return appendActionByID(gameID, actionType, parameters, func(seats []Seat, steps, player int) error {
+		return authorizeAction(seats, userID, player)
+	})
+}
+
+// AppendBotAction appends an action chosen by the bot holding seat after
+// looking at the first steps log entries. It fails with ErrNotYourTurn if the
+// bot no longer acts for the seat, and ErrGameMoved if the log has changed
+// since, e.g. after a takeback.
+func AppendBotAction(gameID, seat, steps int, actionType string, parameters map[string]interface{}) error {
+	return appendActionByID(strconv.Itoa(gameID), actionType, parameters, func(seats []Seat, current, player int) error {
+		if player != seat || !isBot(seats, seat) {
+			return ErrNotYourTurn
+		}
+		if current != steps {
+			return ErrGameMoved
+		}
+		return nil
+	})
+}
+
+// finishIfWon marks the game completed once a player has reached
+// core.WinningLore, recording the winner and the number of turns played
+func finishIfWon(gameData *Game, entries []parser.LogEntry, svc *services.LensServices) {
+	state := core.ReadGameState(entries, svc)
+	winner := state.Winner()
+	if winner == 0 {
+		return
+	}
+	gameData.Status = "completed"
+	gameData.Winner = &winner
+	gameData.Turns = state.Turn
+}
+
+// appendActionByID appends an action, calling authorize with the game's
+// seats, log length and acting player first if set. The game row stays
+// locked from reading the log until the new log is written, so concurrent
+// actions and takebacks are applied one after another.
+func appendActionByID(gameID, actionType string, parameters map[string]interface{}, authorize func(seats []Seat, steps, player int) error) error {
 	id := 0
 	if _, err := fmt.Sscanf(gameID, "%d", &id); err != nil {
 		return fmt.Errorf("invalid game ID: %s", gameID)
 	}
 
+	// Load the game
+	gameData, err := LoadGame(id)
+	if err != nil {
+		return fmt.Errorf("failed to load game: %w", err)
+	}
+
 	db := database.GetDB()
-	_, err := db.Exec("UPDATE games SET log_content = $1, modified_at = NOW() WHERE id = $2",
-		newLogContent, id)
+	tx, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to update game log: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-// AppendActionToGameByID appends an action to a game log by ID
-func AppendActionToGameByID(gameID, actionType string, parameters map[string]interface{}) error {
-	// Load the game
-	gameData, err := LoadGameByID(gameID)
+	err = tx.QueryRow(`SELECT log_content FROM games WHERE id = $1 FOR UPDATE`, id).Scan(&gameData.LogContent)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("failed to load game: game not found: %d", id)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to load game: %w", err)
+		return fmt.Errorf("failed to load game log: %w", err)
 	}
 
 	// Parse current log to get the current state
@@ -560,7 +679,10 @@ func AppendActionToGameByID(gameID, actionType string, parameters map[string]int
 	}
 
 	// Use lens to get current game state instead of manual parsing
-	processor := lens.New()
+	processor, err := LensProcessor(gameData)
+	if err != nil {
+		return err
+	}
 	gameStateData, err := processor.Lens("gameState", entries)
 	if err != nil {
 		return fmt.Errorf("failed to get game state: %w", err)
@@ -569,72 +691,55 @@ func AppendActionToGameByID(gameID, actionType string, parameters map[string]int
 	gameState := gameStateData.(map[string]interface{})
 	currentPlayer := gameState["currentPlayer"].(int)
 
-	// Create the new log entry in event-sourcing format
-	eventName := mapActionToEventName(actionType)
-	if currentPlayer > 0 {
-		parameters["player"] = currentPlayer
+	// Response actions name their player; everything else is taken by the active player
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	actingPlayer := currentPlayer
+	if IsResponseAction(actionType) {
+		actingPlayer, err = playerParameter(parameters)
+		if err != nil {
+			return err
+		}
+	}
+	if authorize != nil {
+		seats, err := loadSeats(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := authorize(seats, len(entries), actingPlayer); err != nil {
+			return err
+		}
 	}
-	logLine := writeEventLog(eventName, parameters)
 
-	// Start building the updated log content
+	// Create the new log entries in event-sourcing format, with the automatic
+	// draw and turn start following a pass
+	lines := ActionLines(entries, actionType, parameters, actingPlayer, processor.Services(), GameDecks(gameData, entries))
 	newLogContent := gameData.LogContent
 	if newLogContent != "" {
 		newLogContent += "\n"
 	}
-	newLogContent += logLine
-
-	// If this is a pass action, we need to add automatic follow-up actions
-	if actionType == "pass" {
-		currentTurn := gameState["currentTurn"].(int)
-		
-		// Get the next player (who will start their turn)
-		nextPlayer := currentPlayer
-		if currentPlayer == 1 {
-			nextPlayer = 2
-		} else {
-			nextPlayer = 1
-		}
-
-		// Determine the next turn number
-		nextTurn := currentTurn + 1
-
-		// Player 1 doesn't draw on turn 1 (their very first turn), but draws on all other turns
-		// Player 2 always draws at the start of their turns
-		shouldDraw := !(nextTurn == 1 && nextPlayer == 1)
-
-		// Add draw_card action if the player should draw
-		if shouldDraw {
-			// Get next card from deck for the player
-			nextCard, err := getNextCardFromDeck(entries, gameData, nextPlayer)
-			if err == nil && nextCard != "" {
-				drawLogLine := writeEventLog("CardDrawn", map[string]interface{}{
-					"card_id": nextCard,
-					"player":  nextPlayer,
-				})
-				newLogContent += "\n" + drawLogLine
-			}
-		}
+	newLogContent += strings.Join(lines, "\n")
 
-		// Add turn_start action for the next player
-		turnStartLogLine := writeEventLog("TurnStarted", map[string]interface{}{
-			"player": nextPlayer,
-			"turn":   nextTurn,
-		})
-		newLogContent += "\n" + turnStartLogLine
+	newEntries, err := parser.ParseLogContent(newLogContent)
+	if err != nil {
+		return fmt.Errorf("failed to parse game log: %w", err)
 	}
+	finishIfWon(gameData, newEntries, processor.Services())
 
 	// Update the game in database
-	id := 0
-	if _, err := fmt.Sscanf(gameID, "%d", &id); err != nil {
-		return fmt.Errorf("invalid game ID: %s", gameID)
-	}
-
-	db := database.GetDB()
-	_, err = db.Exec("UPDATE games SET log_content = $1, modified_at = NOW() WHERE id = $2",
-		newLogContent, id)
+	_, err = tx.Exec(`
+		UPDATE games SET log_content = $1, status = $2, winner = $3, turns = $4, modified_at = NOW()
+		WHERE id = $5`,
+		newLogContent, gameData.Status, gameData.Winner, gameData.Turns, id)
 	if err != nil {
 		return fmt.Errorf("failed to update game: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit game log: %w", err)
+	}
 
+	recordStepTimes(gameData.ID, len(entries), newLogContent)
+	publishUpdate(gameData, newLogContent, len(entries), events.TypeAppend, processor)
 	return nil
 }
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// DeckSource returns the card list of a player's deck, for drawing
+type DeckSource func(player int) (map[string]int, error)
+
+// instancePrefixes are the instance ID prefixes of permanents by card type
+var instancePrefixes = map[string]string{
+	"Character": "$CHAR_",
+	"Item":      "$ITEM_",
+	"Location":  "$LOC_",
+}
+
+// ActionLines returns the log lines an action taken by actingPlayer appends
+// after the given entries: the action's own event and, for a pass, the next
+// player's draw and turn start. Cards played without an instance are given
+// the next free one. The action is not checked for validity, and draws are
+// skipped when decks is nil or the deck cannot be drawn from.
+func ActionLines(entries []parser.LogEntry, actionType string, parameters map[string]interface{}, actingPlayer int, svc *services.LensServices, decks DeckSource) []string {
+	eventParameters := make(map[string]interface{}, len(parameters)+1)
+	for key, value := range parameters {
+		eventParameters[key] = value
+	}
+	if actingPlayer > 0 {
+		eventParameters["player"] = actingPlayer
+	}
+	if actionType == "play_card" && eventParameters["instance"] == nil {
+		cardID, _ := eventParameters["card_id"].(string)
+		if instance := nextInstance(entries, cardID, svc); instance != "" {
+			eventParameters["instance"] = instance
+		}
+	}
+	lines := []string{writeEventLog(mapActionToEventName(actionType), eventParameters)}
+
+	if actionType != "pass" {
+		return lines
+	}
+
+	currentPlayer := actingPlayer
+	if currentPlayer == 0 {
+		currentPlayer = 1
+	}
+	nextPlayer := 1
+	if currentPlayer == 1 {
+		nextPlayer = 2
+	}
+	nextTurn := currentTurn(entries) + 1
+
+	// Player 1 doesn't draw on turn 1 (their very first turn), but draws on all other turns
+	// Player 2 always draws at the start of their turns
+	if decks != nil && !(nextTurn == 1 && nextPlayer == 1) {
+		if deckCards, err := decks(nextPlayer); err == nil {
+			if nextCard, err := drawNextCard(entries, deckCards, nextPlayer); err == nil {
+				lines = append(lines, writeEventLog("CardDrawn", map[string]interface{}{
+					"card_id": nextCard,
+					"player":  nextPlayer,
+				}))
+			}
+		}
+	}
+
+	return append(lines, writeEventLog("TurnStarted", map[string]interface{}{
+		"player": nextPlayer,
+		"turn":   nextTurn,
+	}))
+}
+
+// ApplyAction returns the entries after an action, as ActionLines would
+// append it. The given entries are left untouched.
+func ApplyAction(entries []parser.LogEntry, actionType string, parameters map[string]interface{}, actingPlayer int, svc *services.LensServices, decks DeckSource) ([]parser.LogEntry, error) {
+	lines := ActionLines(entries, actionType, parameters, actingPlayer, svc, decks)
+	added, err := parser.ParseLogContent(strings.Join(lines, "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse action: %w", err)
+	}
+
+	result := make([]parser.LogEntry, len(entries), len(entries)+len(added))
+	copy(result, entries)
+	for _, entry := range added {
+		entry.Step = len(result)
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// currentTurn returns the turn number of the game after the given entries
+func currentTurn(entries []parser.LogEntry) int {
+	gameState := core.GameStateLens(entries, nil).(map[string]interface{})
+	return gameState["currentTurn"].(int)
+}
+
+// nextInstance returns the next free instance ID for a card entering play,
+// or "" for cards that don't stay in play
+func nextInstance(entries []parser.LogEntry, cardID string, svc *services.LensServices) string {
+	cardData, exists := svc.CardDB.GetCard(cardID)
+	if !exists {
+		return ""
+	}
+	prefix, exists := instancePrefixes[cardData.Type]
+	if !exists {
+		return ""
+	}
+
+	highest := 0
+	for _, entry := range entries {
+		instance := string(entry.GetInstance("instance"))
+		if !strings.HasPrefix(instance, prefix) {
+			continue
+		}
+		var number int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(instance, prefix), "%d", &number); err == nil && number > highest {
+			highest = number
+		}
+	}
+	return fmt.Sprintf("%s%03d", prefix, highest+1)
+}
+
+// drawNextCard determines what card a player draws next: the first card of
+// their deck, shuffled with the game's seed, that has not already been drawn
+func drawNextCard(entries []parser.LogEntry, deckCards map[string]int, player int) (string, error) {
+	seed := 0
+	drawn := make(map[string]int)
+	for _, entry := range entries {
+		switch entry.Event {
+		case parser.GameStarted:
+			seed = entry.GetInt("seed")
+		case parser.OpeningHandsDrawn:
+			for _, cardID := range entry.GetStringSlice(fmt.Sprintf("p%d", player)) {
+				drawn[cardID]++
+			}
+		case parser.CardDrawn:
+			if entry.GetPlayer() != player {
+				continue
+			}
+			cardID := entry.GetCard("card_id")
+			if cardID == "" {
+				cardID = entry.GetCard("card")
+			}
+			drawn[cardID]++
+		}
+	}
+
+	// Expand deck to card list and shuffle with the same seed
+	cards := expandDeckToCards(deckCards)
+	rng := rand.New(rand.NewSource(int64(seed)))
+	rng.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+
+	for _, cardID := range cards {
+		if drawn[cardID] > 0 {
+			drawn[cardID]--
+			continue
+		}
+		return cardID, nil
+	}
+
+	return "", fmt.Errorf("no more cards available in deck for player %d", player)
+}
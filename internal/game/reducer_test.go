@@ -0,0 +1,103 @@
+package game
+
+import (
+	"testing"
+
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+func testServices(t *testing.T) *services.LensServices {
+	t.Helper()
+	cardDB, err := services.NewOverlayCardDB(services.NewInMemoryCardDB(), map[string]services.CardPatch{
+		"CHR-001": {"Name": "Test Hero", "Type": "Character", "Color": "Amber", "Cost": 1},
+		"ITM-001": {"Name": "Test Lantern", "Type": "Item", "Color": "Amber", "Cost": 1},
+		"ACT-001": {"Name": "Test Song", "Type": "Action", "Color": "Amber", "Cost": 1},
+	})
+	if err != nil {
+		t.Fatalf("NewOverlayCardDB: %v", err)
+	}
+	return &services.LensServices{CardDB: cardDB}
+}
+
+func parseLog(t *testing.T, content string) []parser.LogEntry {
+	t.Helper()
+	entries, err := parser.ParseLogContent(content)
+	if err != nil {
+		t.Fatalf("ParseLogContent: %v", err)
+	}
+	return entries
+}
+
+const setup = `GameStarted p1_deck="A" p2_deck="B" seed=1
+DecksShuffled seed=1
+OpeningHandsDrawn p1="\"CHR-001,CHR-001,ITM-001,ACT-001\"" p2="\"CHR-001\""
+TurnStarted player=1 turn=1
+`
+
+func TestApplyActionAssignsInstances(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    string
+		cardID string
+		want   string
+	}{
+		{"first character", setup, "CHR-001", "$CHAR_001"},
+		// Logs written before instances were assigned have none to continue from
+		{"after legacy play", setup + `CardPlayed card_id="CHR-001" player=1` + "\n", "CHR-001", "$CHAR_001"},
+		{"continues numbering", setup + `CardPlayed card_id="CHR-001" instance="$CHAR_002" player=1` + "\n", "CHR-001", "$CHAR_003"},
+		{"numbered per type", setup + `CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1` + "\n", "ITM-001", "$ITEM_001"},
+		{"actions leave play", setup, "ACT-001", ""},
+	}
+
+	svc := testServices(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := parseLog(t, tt.log)
+			result, err := ApplyAction(entries, "play_card", map[string]interface{}{"card_id": tt.cardID}, 1, svc, nil)
+			if err != nil {
+				t.Fatalf("ApplyAction: %v", err)
+			}
+			played := result[len(result)-1]
+			if played.Event != parser.CardPlayed {
+				t.Fatalf("appended %s, want CardPlayed", played.Event)
+			}
+			if got := string(played.GetInstance("instance")); got != tt.want {
+				t.Errorf("instance = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrawNextCardCountsCopies(t *testing.T) {
+	// Player 1's opening hand holds two of the three copies and no actions
+	const opening = `GameStarted p1_deck="A" p2_deck="B" seed=1
+OpeningHandsDrawn p1="\"CHR-001,CHR-001\"" p2="\"CHR-001\""
+`
+	deck := map[string]int{"CHR-001": 3, "ACT-001": 1}
+	tests := []struct {
+		name    string
+		log     string
+		want    string
+		wantErr bool
+	}{
+		// Both the opening hand and server-appended draws name drawn copies
+		{"opening hand and card_id draws", opening + "CardDrawn card_id=\"CHR-001\" player=1\n", "ACT-001", false},
+		{"legacy card draws", opening + "CardDrawn card=\"CHR-001\" player=1\n", "ACT-001", false},
+		{"other player's draws", opening + "CardDrawn card_id=\"ACT-001\" player=1\nCardDrawn card_id=\"CHR-001\" player=2\n", "CHR-001", false},
+		{"deck exhausted", opening + "CardDrawn card_id=\"CHR-001\" player=1\nCardDrawn card_id=\"ACT-001\" player=1\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := parseLog(t, tt.log)
+			got, err := drawNextCard(entries, deck, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("drew %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
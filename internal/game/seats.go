@@ -0,0 +1,354 @@
+package game
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"quards/internal/database"
+)
+
+// Errors returned when a user may not act for a seat
+var (
+	ErrNotSeated   = errors.New("you are not seated in this game")
+	ErrNotYourTurn = errors.New("it is not your turn")
+	ErrSeatTaken   = errors.New("seat is already taken")
+	ErrBadInvite   = errors.New("invalid invite token")
+	ErrGameMoved   = errors.New("the game has changed since the action was chosen")
+	ErrNotCreator  = errors.New("only the game's creator or a seated player may do this")
+)
+
+// Seat binds a player number in a game to a user
+type Seat struct {
+	GameID      int        `json:"gameId"`
+	Seat        int        `json:"seat"`
+	UserID      *int       `json:"userId"`
+	Username    string     `json:"username,omitempty"`
+	InviteToken string     `json:"inviteToken,omitempty"` // Only shown to seated players
+	Open        bool       `json:"open"`                  // Anyone may claim the seat
+	Bot         string     `json:"bot,omitempty"`         // Kind of bot the server plays the seat with
+	Joined      *time.Time `json:"joined,omitempty"`
+}
+
+// responseActions may be taken by a seated player outside their turn,
+// e.g. the opponent deciding on a mulligan
+var responseActions = map[string]bool{
+	"mulligan": true,
+}
+
+// IsResponseAction reports whether an action may come from the player who is not active
+func IsResponseAction(actionType string) bool {
+	return responseActions[actionType]
+}
+
+// CreateSeats creates both seats for a game. The creator, if any, takes
+// creatorSeat; the other seat is open to anyone when openSeat is set and
+// otherwise requires its invite token.
+func CreateSeats(gameID, creatorID, creatorSeat int, openSeat bool) ([]Seat, error) {
+	db := database.GetDB()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for seat := 1; seat <= 2; seat++ {
+		token, err := generateInviteToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate invite token: %w", err)
+		}
+
+		var userID interface{}
+		var joined interface{}
+		if creatorID > 0 && seat == creatorSeat {
+			userID = creatorID
+			joined = time.Now()
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO game_seats (game_id, seat, user_id, invite_token, open, joined_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			gameID, seat, userID, token, openSeat && seat != creatorSeat, joined)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create seat %d: %w", seat, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit seats: %w", err)
+	}
+
+	return LoadSeats(gameID)
+}
+
+// LoadSeats returns a game's seats ordered by seat number. Games created
+// before seats existed have none.
+func LoadSeats(gameID int) ([]Seat, error) {
+	return loadSeats(database.GetDB(), gameID)
+}
+
+// loadSeats reads a game's seats through db or an open transaction
+func loadSeats(q querier, gameID int) ([]Seat, error) {
+	rows, err := q.Query(`
+		SELECT s.game_id, s.seat, s.user_id, COALESCE(u.username, ''), s.invite_token, s.open,
+		       COALESCE(s.bot, ''), s.joined_at
+		FROM game_seats s LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.game_id = $1 ORDER BY s.seat`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seats: %w", err)
+	}
+	defer rows.Close()
+
+	seats := []Seat{}
+	for rows.Next() {
+		var seat Seat
+		err := rows.Scan(&seat.GameID, &seat.Seat, &seat.UserID, &seat.Username,
+			&seat.InviteToken, &seat.Open, &seat.Bot, &seat.Joined)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, rows.Err()
+}
+
+// ClaimSeat seats a user if the seat is free and either open or the token matches
+func ClaimSeat(gameID, seat, userID int, token string) error {
+	db := database.GetDB()
+
+	var current *int
+	var inviteToken string
+	var open, bot bool
+	err := db.QueryRow(`
+		SELECT user_id, invite_token, open, bot IS NOT NULL FROM game_seats
+		WHERE game_id = $1 AND seat = $2`, gameID, seat).Scan(&current, &inviteToken, &open, &bot)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("seat %d not found in game %d", seat, gameID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load seat: %w", err)
+	}
+
+	if current != nil {
+		if *current == userID {
+			return nil
+		}
+		return ErrSeatTaken
+	}
+	if bot {
+		return ErrSeatTaken
+	}
+	if !open && token != inviteToken {
+		return ErrBadInvite
+	}
+
+	// Guard against a concurrent claim
+	result, err := db.Exec(`
+		UPDATE game_seats SET user_id = $3, joined_at = NOW()
+		WHERE game_id = $1 AND seat = $2 AND user_id IS NULL AND bot IS NULL`, gameID, seat, userID)
+	if err != nil {
+		return fmt.Errorf("failed to claim seat: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSeatTaken
+	}
+	return nil
+}
+
+// LeaveSeat frees a seat held by the user
+func LeaveSeat(gameID, seat, userID int) error {
+	db := database.GetDB()
+
+	result, err := db.Exec(`
+		UPDATE game_seats SET user_id = NULL, joined_at = NULL
+		WHERE game_id = $1 AND seat = $2 AND user_id = $3`, gameID, seat, userID)
+	if err != nil {
+		return fmt.Errorf("failed to leave seat: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotSeated
+	}
+	return nil
+}
+
+// InviteToSeat replaces a free seat's invite token and sets whether it is
+// open, returning the updated seat. Only a seated player may invite.
+func InviteToSeat(gameID, seat, userID int, open bool) (*Seat, error) {
+	seats, err := LoadSeats(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if SeatOf(seats, userID) == 0 {
+		return nil, ErrNotSeated
+	}
+
+	token, err := generateInviteToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	db := database.GetDB()
+	result, err := db.Exec(`
+		UPDATE game_seats SET invite_token = $3, open = $4
+		WHERE game_id = $1 AND seat = $2 AND user_id IS NULL AND bot IS NULL`, gameID, seat, token, open)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update invite: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, ErrSeatTaken
+	}
+
+	seats, err = LoadSeats(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return &seats[seat-1], nil
+}
+
+// AssignBot hands a free seat to a bot of the given kind, or with an empty
+// kind takes the bot off the seat again. Only the game's creator or a seated
+// player may do so. The kind is not checked here.
+func AssignBot(gameID, seat, userID int, kind string) error {
+	if userID == 0 {
+		return ErrNotCreator
+	}
+	seats, err := LoadSeats(gameID)
+	if err != nil {
+		return err
+	}
+	if SeatOf(seats, userID) == 0 {
+		var creatorID sql.NullInt64
+		err := database.GetDB().QueryRow(`SELECT created_by FROM games WHERE id = $1`, gameID).Scan(&creatorID)
+		if err != nil {
+			return fmt.Errorf("failed to load game creator: %w", err)
+		}
+		if !creatorID.Valid || int(creatorID.Int64) != userID {
+			return ErrNotCreator
+		}
+	}
+
+	return SeatBot(gameID, seat, kind)
+}
+
+// SeatBot hands a free seat to a bot without checking who asks, for games
+// being created with a bot
+func SeatBot(gameID, seat int, kind string) error {
+	db := database.GetDB()
+	result, err := db.Exec(`
+		UPDATE game_seats SET bot = NULLIF($3, ''), open = false
+		WHERE game_id = $1 AND seat = $2 AND user_id IS NULL`, gameID, seat, kind)
+	if err != nil {
+		return fmt.Errorf("failed to assign bot: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSeatTaken
+	}
+	return nil
+}
+
+// botSeat returns the only seat held by a bot, or 0 when none or both are
+func botSeat(seats []Seat) int {
+	found := 0
+	for _, seat := range seats {
+		if seat.Bot != "" {
+			if found > 0 {
+				return 0
+			}
+			found = seat.Seat
+		}
+	}
+	return found
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// isBot reports whether a bot holds the seat
+func isBot(seats []Seat, seat int) bool {
+	for _, s := range seats {
+		if s.Seat == seat {
+			return s.Bot != ""
+		}
+	}
+	return false
+}
+
+// SeatOf returns the first seat the user holds, or 0
+func SeatOf(seats []Seat, userID int) int {
+	for _, seat := range seats {
+		if seat.UserID != nil && *seat.UserID == userID {
+			return seat.Seat
+		}
+	}
+	return 0
+}
+
+// isSeated reports whether any seat of the game has been taken, which turns
+// on per-seat authorization. Unseated games stay open for solo log building.
+func isSeated(seats []Seat) bool {
+	for _, seat := range seats {
+		if seat.UserID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// MayManage reports whether a user may manage a game, such as deleting it:
+// only seated players may once a seat is taken
+func MayManage(seats []Seat, userID int) bool {
+	return !isSeated(seats) || SeatOf(seats, userID) > 0
+}
+
+// authorizeAction checks that userID may act as player in a seated game.
+// Nobody may act for a bot.
+func authorizeAction(seats []Seat, userID, player int) error {
+	for _, seat := range seats {
+		if seat.Seat == player && seat.Bot != "" {
+			return ErrNotYourTurn
+		}
+	}
+	if !isSeated(seats) {
+		return nil
+	}
+	for _, seat := range seats {
+		if seat.Seat == player {
+			if seat.UserID != nil && *seat.UserID == userID {
+				return nil
+			}
+			break
+		}
+	}
+	if SeatOf(seats, userID) == 0 {
+		return ErrNotSeated
+	}
+	return ErrNotYourTurn
+}
+
+// HideInviteTokens blanks invite tokens unless the viewer holds a seat
+func HideInviteTokens(seats []Seat, viewerID int) []Seat {
+	if viewerID > 0 && SeatOf(seats, viewerID) > 0 {
+		return seats
+	}
+	hidden := make([]Seat, len(seats))
+	for i, seat := range seats {
+		seat.InviteToken = ""
+		hidden[i] = seat
+	}
+	return hidden
+}
+
+func generateInviteToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"quards/internal/database"
+	"quards/internal/parser"
+)
+
+// ErrNoCasterView is returned when a caster view is requested for a game that does not allow it
+var ErrNoCasterView = errors.New("this game does not allow a caster view")
+
+// SpectatorSettings control what spectators see of a game while it is being
+// played. With both delays set, spectators are held back by whichever shows less.
+type SpectatorSettings struct {
+	DelayTurns   int  `json:"delayTurns"`   // Spectators see the game as of this many turns ago
+	DelayMinutes int  `json:"delayMinutes"` // Spectators see steps this many minutes after they happen
+	CasterView   bool `json:"casterView"`   // Casters may see both hands, on the same delay
+}
+
+// Validate checks the settings
+func (s SpectatorSettings) Validate() error {
+	if s.DelayTurns < 0 || s.DelayMinutes < 0 {
+		return fmt.Errorf("spectator delays cannot be negative")
+	}
+	return nil
+}
+
+// UpdateSpectatorSettings changes a game's spectator settings
+func UpdateSpectatorSettings(gameID int, settings SpectatorSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	result, err := db.Exec(`
+		UPDATE games SET spectator_delay_turns = $2, spectator_delay_minutes = $3, caster_view = $4, modified_at = NOW()
+		WHERE id = $1`, gameID, settings.DelayTurns, settings.DelayMinutes, settings.CasterView)
+	if err != nil {
+		return fmt.Errorf("failed to update spectator settings: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("game not found: %d", gameID)
+	}
+	return nil
+}
+
+// ReleasedSteps returns how many log entries spectators may see at the given time
+func ReleasedSteps(gameData *Game, entries []parser.LogEntry, now time.Time) (int, error) {
+	released := len(entries)
+
+	if turns := gameData.Spectators.DelayTurns; turns > 0 {
+		released = min(released, turnDelayedSteps(entries, turns))
+	}
+
+	if minutes := gameData.Spectators.DelayMinutes; minutes > 0 {
+		cutoff := now.Add(-time.Duration(minutes) * time.Minute)
+		if gameData.Created.After(cutoff) {
+			return 0, nil
+		}
+
+		// The first step recorded after the cutoff is the first one held back
+		db := database.GetDB()
+		var firstHeld sql.NullInt64
+		err := db.QueryRow(`
+			SELECT MIN(step) FROM game_step_times
+			WHERE game_id = $1 AND recorded_at > $2`, gameData.ID, cutoff).Scan(&firstHeld)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load step times: %w", err)
+		}
+		if firstHeld.Valid {
+			released = min(released, int(firstHeld.Int64))
+		}
+	}
+
+	return released, nil
+}
+
+// turnDelayedSteps holds back the last turns turns, counting the one in
+// progress, by returning the number of entries before the start of the
+// earliest of them. Before enough turns have started, only the setup is shown.
+func turnDelayedSteps(entries []parser.LogEntry, turns int) int {
+	var turnStarts []int
+	for i, entry := range entries {
+		if entry.Event == parser.TurnStarted {
+			turnStarts = append(turnStarts, i)
+		}
+	}
+
+	if len(turnStarts) == 0 {
+		return len(entries)
+	}
+	if len(turnStarts) < turns {
+		return turnStarts[0]
+	}
+	return turnStarts[len(turnStarts)-turns]
+}
+
+// recordStepTimes records that the entries of logContent from fromStep on
+// were just added
+func recordStepTimes(gameID, fromStep int, logContent string) {
+	entries, err := parser.ParseLogContent(logContent)
+	if err != nil {
+		log.Printf("game %d: failed to parse log for step times: %v", gameID, err)
+		return
+	}
+
+	forgetStepTimes(gameID, fromStep)
+	if len(entries) <= fromStep {
+		return
+	}
+
+	db := database.GetDB()
+	_, err = db.Exec(`
+		INSERT INTO game_step_times (game_id, step)
+		SELECT $1, generate_series($2::int, $3::int)`, gameID, fromStep, len(entries)-1)
+	if err != nil {
+		log.Printf("game %d: failed to record step times: %v", gameID, err)
+	}
+}
+
+// forgetStepTimes drops the recorded times of steps from fromStep on, after
+// the log was cut back
+func forgetStepTimes(gameID, fromStep int) {
+	db := database.GetDB()
+	_, err := db.Exec(`DELETE FROM game_step_times WHERE game_id = $1 AND step >= $2`, gameID, fromStep)
+	if err != nil {
+		log.Printf("game %d: failed to clear step times: %v", gameID, err)
+	}
+}
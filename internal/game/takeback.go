@@ -0,0 +1,323 @@
+package game
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"quards/internal/database"
+	"quards/internal/events"
+	"quards/internal/parser"
+)
+
+// Takeback statuses
+const (
+	TakebackPending  = "pending"
+	TakebackApplied  = "applied"
+	TakebackRejected = "rejected"
+	TakebackExpired  = "expired" // The game moved on before the takeback was approved
+)
+
+// Errors returned for takebacks that cannot be requested or resolved
+var (
+	ErrInvalidTakeback  = errors.New("invalid takeback step")
+	ErrTakebackPending  = errors.New("a takeback is already awaiting approval")
+	ErrTakebackResolved = errors.New("takeback is no longer pending")
+	ErrTakebackStale    = errors.New("the game has moved on since the takeback was requested")
+	ErrNotOpponent      = errors.New("only the opponent can approve a takeback")
+)
+
+// Takeback is an audited request to cut a game's log back to an earlier step.
+// The removed entries are kept so the full history can be reconstructed.
+type Takeback struct {
+	ID          int        `json:"id"`
+	GameID      int        `json:"gameId"`
+	FromStep    int        `json:"fromStep"` // Log length when requested
+	ToStep      int        `json:"toStep"`   // Log length after the takeback
+	RemovedLog  string     `json:"removedLog"`
+	RequestedBy *int       `json:"requestedBy"`
+	ResolvedBy  *int       `json:"resolvedBy"`
+	Status      string     `json:"status"`
+	Created     time.Time  `json:"created"`
+	Resolved    *time.Time `json:"resolved,omitempty"`
+}
+
+// RequestTakeback asks to cut a game's log back to its first toStep entries.
+// When both seats are held by different users the takeback waits for the
+// opponent's approval; otherwise it is applied immediately. The setup before
+// the first turn cannot be taken back.
+func RequestTakeback(gameID, userID, toStep int) (*Takeback, error) {
+	db := database.GetDB()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the game serializes requests, so only one can be pending
+	var logContent string
+	err = tx.QueryRow(`SELECT log_content FROM games WHERE id = $1 FOR UPDATE`, gameID).Scan(&logContent)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("game not found: %d", gameID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game log: %w", err)
+	}
+	entries, err := parser.ParseLogContent(logContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse game log: %w", err)
+	}
+
+	firstTurn := len(entries)
+	for i, entry := range entries {
+		if entry.Event == parser.TurnStarted {
+			firstTurn = i
+			break
+		}
+	}
+	if toStep <= firstTurn || toStep >= len(entries) {
+		return nil, fmt.Errorf("%w: step must be after the first turn starts (%d) and before the end of the log (%d)",
+			ErrInvalidTakeback, firstTurn+1, len(entries))
+	}
+
+	seats, err := loadSeats(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if isSeated(seats) && SeatOf(seats, userID) == 0 {
+		return nil, ErrNotSeated
+	}
+
+	// Requests the game has moved past can no longer be approved
+	_, err = tx.Exec(`
+		UPDATE game_takebacks SET status = $3, resolved_at = NOW()
+		WHERE game_id = $1 AND status = $4 AND from_step <> $2`,
+		gameID, len(entries), TakebackExpired, TakebackPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire takebacks: %w", err)
+	}
+
+	var pending int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM game_takebacks WHERE game_id = $1 AND status = $2`,
+		gameID, TakebackPending).Scan(&pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pending takebacks: %w", err)
+	}
+	if pending > 0 {
+		return nil, ErrTakebackPending
+	}
+
+	_, removed := splitLog(logContent, toStep)
+
+	var takebackID int
+	err = tx.QueryRow(`
+		INSERT INTO game_takebacks (game_id, from_step, to_step, removed_log, requested_by, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		gameID, len(entries), toStep, removed, nullableUser(userID), TakebackPending).Scan(&takebackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record takeback: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit takeback: %w", err)
+	}
+
+	if !needsApproval(seats) {
+		if err := applyTakeback(gameID, takebackID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return LoadTakeback(gameID, takebackID)
+}
+
+// ApproveTakeback applies a pending takeback on behalf of the opponent of
+// the user who requested it
+func ApproveTakeback(gameID, takebackID, userID int) (*Takeback, error) {
+	takeback, err := LoadTakeback(gameID, takebackID)
+	if err != nil {
+		return nil, err
+	}
+	if takeback.Status != TakebackPending {
+		return nil, ErrTakebackResolved
+	}
+
+	seats, err := LoadSeats(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if SeatOf(seats, userID) == 0 {
+		return nil, ErrNotSeated
+	}
+	if takeback.RequestedBy != nil && *takeback.RequestedBy == userID {
+		return nil, ErrNotOpponent
+	}
+
+	if err := applyTakeback(gameID, takebackID, userID); err != nil {
+		return nil, err
+	}
+	return LoadTakeback(gameID, takebackID)
+}
+
+// RejectTakeback declines a pending takeback. Either seated player may
+// reject it, so the requester can also withdraw it.
+func RejectTakeback(gameID, takebackID, userID int) (*Takeback, error) {
+	seats, err := LoadSeats(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if isSeated(seats) && SeatOf(seats, userID) == 0 {
+		return nil, ErrNotSeated
+	}
+
+	db := database.GetDB()
+	result, err := db.Exec(`
+		UPDATE game_takebacks SET status = $4, resolved_by = $3, resolved_at = NOW()
+		WHERE game_id = $1 AND id = $2 AND status = $5`,
+		gameID, takebackID, nullableUser(userID), TakebackRejected, TakebackPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject takeback: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, ErrTakebackResolved
+	}
+	return LoadTakeback(gameID, takebackID)
+}
+
+// LoadTakeback loads a takeback of a game
+func LoadTakeback(gameID, takebackID int) (*Takeback, error) {
+	db := database.GetDB()
+
+	row := db.QueryRow(`
+		SELECT id, game_id, from_step, to_step, removed_log, requested_by, resolved_by, status, created_at, resolved_at
+		FROM game_takebacks WHERE game_id = $1 AND id = $2`, gameID, takebackID)
+	takeback, err := scanTakeback(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("takeback %d not found in game %d", takebackID, gameID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load takeback: %w", err)
+	}
+	return takeback, nil
+}
+
+// ListTakebacks returns a game's takebacks, oldest first
+func ListTakebacks(gameID int) ([]Takeback, error) {
+	db := database.GetDB()
+
+	rows, err := db.Query(`
+		SELECT id, game_id, from_step, to_step, removed_log, requested_by, resolved_by, status, created_at, resolved_at
+		FROM game_takebacks WHERE game_id = $1 ORDER BY created_at, id`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query takebacks: %w", err)
+	}
+	defer rows.Close()
+
+	takebacks := []Takeback{}
+	for rows.Next() {
+		takeback, err := scanTakeback(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan takeback: %w", err)
+		}
+		takebacks = append(takebacks, *takeback)
+	}
+	return takebacks, rows.Err()
+}
+
+// applyTakeback cuts the game log back, provided it has not changed since
+// the takeback was requested, and publishes the reset to subscribers
+func applyTakeback(gameID, takebackID, resolverID int) error {
+	db := database.GetDB()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var fromStep, toStep int
+	err = tx.QueryRow(`SELECT from_step, to_step FROM game_takebacks WHERE id = $1 AND status = $2 FOR UPDATE`,
+		takebackID, TakebackPending).Scan(&fromStep, &toStep)
+	if err == sql.ErrNoRows {
+		return ErrTakebackResolved
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load takeback: %w", err)
+	}
+
+	var logContent string
+	err = tx.QueryRow(`SELECT log_content FROM games WHERE id = $1 FOR UPDATE`, gameID).Scan(&logContent)
+	if err != nil {
+		return fmt.Errorf("failed to load game log: %w", err)
+	}
+	entries, err := parser.ParseLogContent(logContent)
+	if err != nil {
+		return fmt.Errorf("failed to parse game log: %w", err)
+	}
+
+	if len(entries) != fromStep {
+		_, err = tx.Exec(`UPDATE game_takebacks SET status = $2, resolved_at = NOW() WHERE id = $1`,
+			takebackID, TakebackExpired)
+		if err != nil {
+			return fmt.Errorf("failed to expire takeback: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit takeback: %w", err)
+		}
+		return ErrTakebackStale
+	}
+
+	kept, _ := splitLog(logContent, toStep)
+	_, err = tx.Exec(`UPDATE games SET log_content = $2, modified_at = NOW() WHERE id = $1`, gameID, kept)
+	if err != nil {
+		return fmt.Errorf("failed to update game log: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE game_takebacks SET status = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE id = $1`, takebackID, TakebackApplied, nullableUser(resolverID))
+	if err != nil {
+		return fmt.Errorf("failed to update takeback: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit takeback: %w", err)
+	}
+
+	forgetStepTimes(gameID, toStep)
+	if gameData, err := LoadGame(gameID); err == nil {
+		publishUpdate(gameData, kept, 0, events.TypeReset, nil)
+	}
+	return nil
+}
+
+// needsApproval reports whether both seats are held by different users, so
+// a takeback needs the opponent's approval
+func needsApproval(seats []Seat) bool {
+	var users []int
+	for _, seat := range seats {
+		if seat.UserID != nil {
+			users = append(users, *seat.UserID)
+		}
+	}
+	return len(users) == 2 && users[0] != users[1]
+}
+
+// nullableUser stores anonymous requests as NULL
+func nullableUser(userID int) interface{} {
+	if userID > 0 {
+		return userID
+	}
+	return nil
+}
+
+func scanTakeback(row interface {
+	Scan(dest ...interface{}) error
+}) (*Takeback, error) {
+	var takeback Takeback
+	err := row.Scan(&takeback.ID, &takeback.GameID, &takeback.FromStep, &takeback.ToStep, &takeback.RemovedLog,
+		&takeback.RequestedBy, &takeback.ResolvedBy, &takeback.Status, &takeback.Created, &takeback.Resolved)
+	if err != nil {
+		return nil, err
+	}
+	return &takeback, nil
+}
@@ -0,0 +1,166 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// View is how a user sees a game: whose hidden information they see, and
+// whether they follow it on the spectator delay
+type View struct {
+	Viewer  *services.Viewer // nil sees everything
+	Delayed bool
+}
+
+// ViewFor returns how a user sees the game. Seated users see their own hand,
+// anyone else is a delayed spectator; a caster is a delayed spectator who
+// sees both hands, if the game allows it. Everything is revealed once the
+// game is completed, and in games nobody has sat down in.
+func ViewFor(gameData *Game, userID int, caster bool) (*View, error) {
+	if gameData.Status == "completed" {
+		return &View{}, nil
+	}
+
+	seats, err := LoadSeats(gameData.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSeated(seats) {
+		// Whoever plays against a lone bot sees the game from the other seat
+		if bot := botSeat(seats); bot > 0 {
+			return &View{Viewer: &services.Viewer{Player: 3 - bot}}, nil
+		}
+		return &View{}, nil
+	}
+
+	if userID > 0 {
+		if seat := SeatOf(seats, userID); seat > 0 {
+			return &View{Viewer: &services.Viewer{Player: seat}}, nil
+		}
+	}
+	if caster {
+		if !gameData.Spectators.CasterView {
+			return nil, ErrNoCasterView
+		}
+		return &View{Viewer: services.Caster, Delayed: true}, nil
+	}
+	return &View{Viewer: services.Spectator, Delayed: true}, nil
+}
+
+// VisibleSteps returns how many of the game's log entries the view shows
+func (v *View) VisibleSteps(gameData *Game, entries []parser.LogEntry) (int, error) {
+	if !v.Delayed {
+		return len(entries), nil
+	}
+	return ReleasedSteps(gameData, entries, time.Now())
+}
+
+// Redacted returns a copy of the game as the view shows it, with the log cut
+// to the visible steps and hidden information removed from it. Delayed views
+// never see the seed, which would give away the draws still held back.
+func Redacted(gameData *Game, view *View) (*Game, error) {
+	if view.Viewer == nil && !view.Delayed {
+		return gameData, nil
+	}
+
+	entries, err := parser.ParseLogContent(gameData.LogContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse game log: %w", err)
+	}
+	steps, err := view.VisibleSteps(gameData, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// A delayed view that sees both hands still may not see the seed
+	viewer := view.Viewer
+	if viewer == nil {
+		viewer = services.Caster
+	}
+
+	redacted := *gameData
+	visible, _ := splitLog(gameData.LogContent, steps)
+	redacted.LogContent = RedactLog(visible, viewer)
+	redacted.Seed = nil
+	return &redacted, nil
+}
+
+// splitLog splits raw log content after its first steps entries
+func splitLog(logContent string, steps int) (string, string) {
+	lines := strings.Split(logContent, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if steps == 0 {
+			return strings.Join(append(lines[:i:i], ""), "\n"), strings.Join(lines[i:], "\n")
+		}
+		steps--
+	}
+	return logContent, ""
+}
+
+// RedactLog removes what the viewer may not see from raw log content.
+// Parameters that stay visible are kept exactly as written.
+func RedactLog(logContent string, viewer *services.Viewer) string {
+	if viewer == nil {
+		return logContent
+	}
+
+	lines := strings.Split(logContent, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		entries, err := parser.ParseLogContent(trimmed)
+		if err != nil || len(entries) != 1 {
+			continue
+		}
+		entry := entries[0]
+		redacted := core.RedactEntry(entry, viewer)
+
+		fields := strings.Fields(trimmed)
+		kept := []string{fields[0]}
+		written := make(map[string]bool)
+		for _, field := range fields[1:] {
+			key, _, found := strings.Cut(field, "=")
+			if !found {
+				kept = append(kept, field)
+				continue
+			}
+			value, ok := redacted.Parameters[key]
+			if !ok {
+				continue
+			}
+			if value != entry.Parameters[key] {
+				field = fmt.Sprintf("%s=%q", key, value)
+			}
+			kept = append(kept, field)
+			written[key] = true
+		}
+
+		// Parameters added by redaction, such as opening hand sizes
+		var added []string
+		for key := range redacted.Parameters {
+			if !written[key] {
+				added = append(added, key)
+			}
+		}
+		sort.Strings(added)
+		for _, key := range added {
+			kept = append(kept, fmt.Sprintf("%s=%s", key, redacted.Parameters[key]))
+		}
+
+		lines[i] = strings.Join(kept, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,279 @@
+package game
+
+import (
+	"testing"
+
+	"quards/internal/lens/services"
+)
+
+const visibilityLog = `GameStarted p1_deck="A" p2_deck="B" seed=42
+DecksShuffled seed=42
+OpeningHandsDrawn p1="\"CHR-001,ACT-001\"" p2="\"CHR-001,CHR-001,ITM-001\""
+HandMulliganed cards="CHR-001,CHR-001" player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn card_id="ACT-001" player=2
+TurnStarted player=2 turn=2
+`
+
+func TestRedactLog(t *testing.T) {
+	tests := []struct {
+		name   string
+		viewer *services.Viewer
+		want   string
+	}{
+		{
+			name:   "nil viewer",
+			viewer: nil,
+			want:   visibilityLog,
+		},
+		{
+			name:   "player 1",
+			viewer: &services.Viewer{Player: 1},
+			want: `GameStarted p1_deck="A" p2_deck="B"
+DecksShuffled
+OpeningHandsDrawn p1="\"CHR-001,ACT-001\"" p2_count=3
+HandMulliganed player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn player=2
+TurnStarted player=2 turn=2
+`,
+		},
+		{
+			name:   "player 2",
+			viewer: &services.Viewer{Player: 2},
+			want: `GameStarted p1_deck="A" p2_deck="B"
+DecksShuffled
+OpeningHandsDrawn p2="\"CHR-001,CHR-001,ITM-001\"" p1_count=2
+HandMulliganed cards="CHR-001,CHR-001" player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn card_id="ACT-001" player=2
+TurnStarted player=2 turn=2
+`,
+		},
+		{
+			name:   "spectator",
+			viewer: services.Spectator,
+			want: `GameStarted p1_deck="A" p2_deck="B"
+DecksShuffled
+OpeningHandsDrawn p1_count=2 p2_count=3
+HandMulliganed player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn player=2
+TurnStarted player=2 turn=2
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactLog(visibilityLog, tt.viewer); got != tt.want {
+				t.Errorf("RedactLog() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      int
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:       "setup only",
+			steps:      3,
+			wantBefore: "GameStarted p1_deck=\"A\" p2_deck=\"B\" seed=42\nDecksShuffled seed=42\nOpeningHandsDrawn p1=\"\\\"CHR-001,ACT-001\\\"\" p2=\"\\\"CHR-001,CHR-001,ITM-001\\\"\"\n",
+			wantAfter:  "HandMulliganed cards=\"CHR-001,CHR-001\" player=2\nTurnStarted player=1 turn=1\n# Comments are kept\nCardPlayed card_id=\"CHR-001\" instance=\"$CHAR_001\" player=1\nTurnPassed player=1\nCardDrawn card_id=\"ACT-001\" player=2\nTurnStarted player=2 turn=2\n",
+		},
+		{
+			// Comments stay with the entries before them
+			name:       "comment before cut",
+			steps:      5,
+			wantBefore: "GameStarted p1_deck=\"A\" p2_deck=\"B\" seed=42\nDecksShuffled seed=42\nOpeningHandsDrawn p1=\"\\\"CHR-001,ACT-001\\\"\" p2=\"\\\"CHR-001,CHR-001,ITM-001\\\"\"\nHandMulliganed cards=\"CHR-001,CHR-001\" player=2\nTurnStarted player=1 turn=1\n# Comments are kept\n",
+			wantAfter:  "CardPlayed card_id=\"CHR-001\" instance=\"$CHAR_001\" player=1\nTurnPassed player=1\nCardDrawn card_id=\"ACT-001\" player=2\nTurnStarted player=2 turn=2\n",
+		},
+		{
+			name:       "nothing",
+			steps:      0,
+			wantBefore: "",
+			wantAfter:  visibilityLog,
+		},
+		{
+			name:       "everything",
+			steps:      9,
+			wantBefore: visibilityLog,
+			wantAfter:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := splitLog(visibilityLog, tt.steps)
+			if before != tt.wantBefore {
+				t.Errorf("before =\n%q\nwant\n%q", before, tt.wantBefore)
+			}
+			if after != tt.wantAfter {
+				t.Errorf("after =\n%q\nwant\n%q", after, tt.wantAfter)
+			}
+		})
+	}
+}
+
+// Delayed spectators see the log cut to the released steps, then redacted
+func TestRedactedDelayedSpectator(t *testing.T) {
+	seed := 42
+	gameData := &Game{
+		Seed:       &seed,
+		LogContent: visibilityLog,
+		Spectators: SpectatorSettings{DelayTurns: 1},
+	}
+
+	tests := []struct {
+		name     string
+		view     *View
+		want     string
+		wantSeed bool
+	}{
+		{
+			name: "spectator",
+			view: &View{Viewer: services.Spectator, Delayed: true},
+			want: `GameStarted p1_deck="A" p2_deck="B"
+DecksShuffled
+OpeningHandsDrawn p1_count=2 p2_count=3
+HandMulliganed player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn player=2
+`,
+		},
+		{
+			name: "caster",
+			view: &View{Viewer: services.Caster, Delayed: true},
+			want: `GameStarted p1_deck="A" p2_deck="B"
+DecksShuffled
+OpeningHandsDrawn p1="\"CHR-001,ACT-001\"" p2="\"CHR-001,CHR-001,ITM-001\""
+HandMulliganed cards="CHR-001,CHR-001" player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn card_id="ACT-001" player=2
+`,
+		},
+		{
+			name: "delayed without viewer",
+			view: &View{Delayed: true},
+			want: `GameStarted p1_deck="A" p2_deck="B"
+DecksShuffled
+OpeningHandsDrawn p1="\"CHR-001,ACT-001\"" p2="\"CHR-001,CHR-001,ITM-001\""
+HandMulliganed cards="CHR-001,CHR-001" player=2
+TurnStarted player=1 turn=1
+# Comments are kept
+CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+CardDrawn card_id="ACT-001" player=2
+`,
+		},
+		{
+			name:     "unrestricted",
+			view:     &View{},
+			want:     visibilityLog,
+			wantSeed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, err := Redacted(gameData, tt.view)
+			if err != nil {
+				t.Fatalf("Redacted: %v", err)
+			}
+			if redacted.LogContent != tt.want {
+				t.Errorf("log =\n%s\nwant\n%s", redacted.LogContent, tt.want)
+			}
+			if (redacted.Seed != nil) != tt.wantSeed {
+				t.Errorf("seed = %v, want shown %v", redacted.Seed, tt.wantSeed)
+			}
+			if gameData.LogContent != visibilityLog {
+				t.Error("original game modified")
+			}
+		})
+	}
+}
+
+// Once a player reaches the winning lore the game is completed, and its
+// whole log is revealed to players and spectators alike
+func TestWonGameIsRevealed(t *testing.T) {
+	seed := 42
+	tests := []struct {
+		name       string
+		log        string
+		wantStatus string
+		wantWinner int
+	}{
+		{
+			name:       "in progress",
+			log:        visibilityLog + "QuestAttempted card_id=\"CHR-001\" instance=\"$CHAR_001\" lore=19 player=2\n",
+			wantStatus: "in_progress",
+		},
+		{
+			name:       "won",
+			log:        visibilityLog + "QuestAttempted card_id=\"CHR-001\" instance=\"$CHAR_001\" lore=20 player=2\n",
+			wantStatus: "completed",
+			wantWinner: 2,
+		},
+	}
+
+	svc := testServices(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameData := &Game{Seed: &seed, LogContent: tt.log, Status: "in_progress"}
+			finishIfWon(gameData, parseLog(t, tt.log), svc)
+
+			if gameData.Status != tt.wantStatus {
+				t.Fatalf("status = %q, want %q", gameData.Status, tt.wantStatus)
+			}
+			if tt.wantWinner == 0 {
+				if gameData.Winner != nil {
+					t.Errorf("winner = %d, want none", *gameData.Winner)
+				}
+				return
+			}
+			if gameData.Winner == nil || *gameData.Winner != tt.wantWinner {
+				t.Errorf("winner = %v, want %d", gameData.Winner, tt.wantWinner)
+			}
+			if gameData.Turns != 2 {
+				t.Errorf("turns = %d, want 2", gameData.Turns)
+			}
+
+			for _, userID := range []int{0, 1} {
+				view, err := ViewFor(gameData, userID, false)
+				if err != nil {
+					t.Fatalf("ViewFor: %v", err)
+				}
+				redacted, err := Redacted(gameData, view)
+				if err != nil {
+					t.Fatalf("Redacted: %v", err)
+				}
+				if redacted.LogContent != tt.log || redacted.Seed == nil {
+					t.Errorf("user %d sees a redacted log:\n%s", userID, redacted.LogContent)
+				}
+			}
+		})
+	}
+}
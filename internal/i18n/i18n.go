@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when no supported language is requested, and for
+// messages missing from a catalog
+const DefaultLanguage = "en"
+
+//go:embed messages/*.json
+var messageFiles embed.FS
+
+// catalogs maps language -> message key -> message template. Templates use
+// named placeholders such as "{player} inks {card}".
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	result := make(map[string]map[string]string)
+
+	files, err := messageFiles.ReadDir("messages")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: %v", err))
+	}
+	for _, file := range files {
+		data, err := messageFiles.ReadFile(path.Join("messages", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: %v", err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", file.Name(), err))
+		}
+		result[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+
+	return result
+}
+
+// Languages returns the languages that have a message catalog
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Supported reports whether a language has a message catalog
+func Supported(language string) bool {
+	_, exists := catalogs[language]
+	return exists
+}
+
+// Args are the values substituted for a message's placeholders
+type Args map[string]interface{}
+
+// T returns the message for key in the given language, falling back to the
+// default language and then to the key itself
+func T(language, key string, args Args) string {
+	template, exists := catalogs[language][key]
+	if !exists {
+		template, exists = catalogs[DefaultLanguage][key]
+	}
+	if !exists {
+		template = key
+	}
+
+	if len(args) == 0 {
+		return template
+	}
+	replacements := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+// Negotiate picks the supported language for a request from an explicit
+// choice (e.g. ?lang=de) or an Accept-Language header
+func Negotiate(explicit, acceptLanguage string) string {
+	if language := baseLanguage(explicit); Supported(language) {
+		return language
+	}
+
+	type candidate struct {
+		language string
+		quality  float64
+	}
+	var candidates []candidate
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if language := baseLanguage(tag); Supported(language) && quality > 0 {
+			candidates = append(candidates, candidate{language, quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) > 0 {
+		return candidates[0].language
+	}
+	return DefaultLanguage
+}
+
+// baseLanguage reduces a language tag such as "de-AT" to "de"
+func baseLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"quards/internal/i18n"
 	"quards/internal/lens/services"
 	"quards/internal/parser"
 )
@@ -17,10 +18,34 @@ type Action struct {
 
 // AvailableActionsLens generates available actions for the current player (pure function)
 func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServices) interface{} {
-	if len(entries) == 0 {
+	actions := AvailableActions(entries, services)
+	if len(actions) == 0 {
 		return []Action{}
 	}
 
+	// Convert to interface{} slice for JSON compatibility
+	result := make([]interface{}, len(actions))
+	for i, action := range actions {
+		result[i] = map[string]interface{}{
+			"type":        action.Type,
+			"description": action.Description,
+			"parameters":  action.Parameters,
+			"valid":       action.Valid,
+			"reason":      action.Reason,
+		}
+	}
+	return result
+}
+
+// AvailableActions lists the actions the current player can consider, with
+// the reason the invalid ones cannot be taken. Descriptions and reasons are
+// in the services' language.
+func AvailableActions(entries []parser.LogEntry, services *services.LensServices) []Action {
+	if len(entries) == 0 {
+		return nil
+	}
+	language := services.Language
+
 	// Get current game state using lenses
 	zonesData := ZonesLens(entries, services)
 	statsData := PlayerStatsLens(entries, services)
@@ -32,7 +57,11 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 	currentPlayer := getCurrentPlayer(entries)
 	if currentPlayer == 0 {
 		// Game hasn't started or is system turn
-		return []Action{}
+		return nil
+	}
+	if !services.Viewer.CanSee(currentPlayer) {
+		// Only the current player may see the hand their actions come from
+		return nil
 	}
 
 	// Determine current turn number
@@ -43,7 +72,7 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 	// Always available: Pass
 	actions = append(actions, Action{
 		Type:        "pass",
-		Description: "End turn and pass to opponent",
+		Description: i18n.T(language, "actions.pass", nil),
 		Parameters:  map[string]interface{}{},
 		Valid:       true,
 	})
@@ -111,7 +140,7 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 
 			action := Action{
 				Type:        "play_card",
-				Description: fmt.Sprintf("Play %s (Cost: %d)", cardInfo["Name"], cost),
+				Description: i18n.T(language, "actions.play_card", i18n.Args{"card": getCardName(cardId, services.CardDB, language), "cost": cost}),
 				Parameters: map[string]interface{}{
 					"card_id": cardId,
 					"cost":    cost,
@@ -120,7 +149,7 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 			}
 
 			if !canPlay {
-				action.Reason = fmt.Sprintf("Not enough ink (need %d, have %d)", cost, availableInk)
+				action.Reason = i18n.T(language, "actions.not_enough_ink", i18n.Args{"cost": cost, "ink": availableInk})
 			}
 
 			actions = append(actions, action)
@@ -128,13 +157,13 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 			// Card not found in database - still show play action but mark as invalid
 			action := Action{
 				Type:        "play_card",
-				Description: fmt.Sprintf("Play %s", cardId),
+				Description: i18n.T(language, "actions.play_unknown_card", i18n.Args{"card": cardId}),
 				Parameters: map[string]interface{}{
 					"card_id": cardId,
 					"cost":    0,
 				},
 				Valid:  false,
-				Reason: "Card not found in database",
+				Reason: i18n.T(language, "actions.card_not_found", nil),
 			}
 			actions = append(actions, action)
 		}
@@ -153,7 +182,7 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 
 			action := Action{
 				Type:        "ink_card",
-				Description: fmt.Sprintf("Ink %s", cardInfo["Name"]),
+				Description: i18n.T(language, "actions.ink_card", i18n.Args{"card": getCardName(cardId, services.CardDB, language)}),
 				Parameters: map[string]interface{}{
 					"card_id": cardId,
 				},
@@ -161,9 +190,9 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 			}
 
 			if !inkable {
-				action.Reason = "Card is not inkable"
+				action.Reason = i18n.T(language, "actions.not_inkable", nil)
 			} else if !canInkThisTurn {
-				action.Reason = "Already inked a card this turn"
+				action.Reason = i18n.T(language, "actions.already_inked", nil)
 			}
 
 			actions = append(actions, action)
@@ -171,12 +200,12 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 			// Card not found in database - still show ink action but mark as invalid
 			action := Action{
 				Type:        "ink_card",
-				Description: fmt.Sprintf("Ink %s", cardId),
+				Description: i18n.T(language, "actions.ink_card", i18n.Args{"card": cardId}),
 				Parameters: map[string]interface{}{
 					"card_id": cardId,
 				},
 				Valid:  false,
-				Reason: "Card not found in database",
+				Reason: i18n.T(language, "actions.card_not_found", nil),
 			}
 			actions = append(actions, action)
 		}
@@ -245,40 +274,33 @@ func AvailableActionsLens(entries []parser.LogEntry, services *services.LensServ
 
 			action := Action{
 				Type:        "quest",
-				Description: fmt.Sprintf("Quest with %s (Lore: %d)", cardInfo["Name"], loreValue),
+				Description: i18n.T(language, "actions.quest", i18n.Args{"card": getCardName(cardId, services.CardDB, language), "lore": loreValue}),
 				Parameters: map[string]interface{}{
 					"card_id": cardId,
 					"lore":    loreValue,
 				},
 				Valid: canQuest,
 			}
+			if card.InstanceID != "" {
+				// Quest with this copy of the card
+				action.Parameters["instance"] = card.InstanceID
+			}
 
 			if exhausted {
-				action.Reason = "Character is exhausted"
+				action.Reason = i18n.T(language, "actions.exhausted", nil)
 			} else if cardType != "Character" {
-				action.Reason = "Only characters can quest"
+				action.Reason = i18n.T(language, "actions.not_character", nil)
 			} else if !hasLore {
-				action.Reason = "Character has no lore value"
+				action.Reason = i18n.T(language, "actions.no_lore", nil)
 			} else if isWet {
-				action.Reason = "Character is wet (played this turn)"
+				action.Reason = i18n.T(language, "actions.wet", nil)
 			}
 
 			actions = append(actions, action)
 		}
 	}
 
-	// Convert to interface{} slice for JSON compatibility
-	result := make([]interface{}, len(actions))
-	for i, action := range actions {
-		result[i] = map[string]interface{}{
-			"type":        action.Type,
-			"description": action.Description,
-			"parameters":  action.Parameters,
-			"valid":       action.Valid,
-			"reason":      action.Reason,
-		}
-	}
-	return result
+	return actions
 }
 
 // getCurrentPlayer determines which player should act next
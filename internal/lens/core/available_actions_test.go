@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+)
+
+// Descriptions and reasons come from the message catalog in the services' language
+func TestAvailableActionsLanguage(t *testing.T) {
+	entries := parseLog(t, setup+`CardInked card_id="CHR-002" player=1
+`)
+	tests := []struct {
+		language string
+		want     map[string]string // description -> reason
+	}{
+		{"", map[string]string{
+			"End turn and pass to opponent": "",
+			"Play Test Villain (Cost: 2)":   "Not enough ink (need 2, have 1)",
+			"Ink Test Song":                 "Already inked a card this turn",
+		}},
+		{"de", map[string]string{
+			"Zug beenden und an den Gegner abgeben": "",
+			"Test Villain ausspielen (Kosten: 2)":   "Nicht genug Tinte (2 benötigt, 1 verfügbar)",
+			"Test Song ins Tintenfass legen":        "In diesem Zug wurde bereits eine Karte ins Tintenfass gelegt",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			svc := testServices(t)
+			svc.Language = tt.language
+
+			got := make(map[string]string)
+			for _, action := range AvailableActions(entries, svc) {
+				got[action.Description] = action.Reason
+			}
+			for description, reason := range tt.want {
+				gotReason, exists := got[description]
+				if !exists {
+					t.Errorf("no action %q in %v", description, got)
+					continue
+				}
+				if gotReason != reason {
+					t.Errorf("%q reason = %q, want %q", description, gotReason, reason)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"sort"
+
 	"quards/internal/lens/services"
 	"quards/internal/parser"
 )
@@ -11,4 +13,163 @@ func GameStateLens(entries []parser.LogEntry, services *services.LensServices) i
 		"currentPlayer": getCurrentPlayer(entries),
 		"currentTurn":   getCurrentTurn(entries),
 	}
-}
\ No newline at end of file
+}
+
+// WinningLore is the lore a player needs to win the game
+const WinningLore = 20
+
+// GameState is a typed snapshot of a position, for code that reasons about
+// positions rather than displaying them. Hands the viewer may not see are
+// left empty, with only their size in HandCount.
+type GameState struct {
+	CurrentPlayer int            `json:"current_player"`
+	Turn          int            `json:"turn"`
+	Players       [2]PlayerState `json:"players"`
+}
+
+// PlayerState is one player's side of a GameState. Card lists are sorted so
+// positions reached in a different order compare equal.
+type PlayerState struct {
+	Lore             int         `json:"lore"`
+	TotalInk         int         `json:"total_ink"`
+	AvailableInk     int         `json:"available_ink"`
+	AvailableInkings int         `json:"available_inkings"`
+	Hand             []string    `json:"hand"`
+	HandCount        int         `json:"hand_count"`
+	Ink              []string    `json:"ink"`
+	InPlay           []StateCard `json:"in_play"`
+	Deck             int         `json:"deck"`
+	Discard          int         `json:"discard"`
+}
+
+// StateCard is a card in play with its printed stats
+type StateCard struct {
+	CardID     string `json:"card_id"`
+	InstanceID string `json:"instance_id"`
+	Type       string `json:"type"`
+	Exhausted  bool   `json:"exhausted"`
+	TurnPlayed int    `json:"turn_played"`
+	Dry        bool   `json:"dry"` // Played before this turn, so able to quest and challenge
+	Lore       int    `json:"lore"`
+	Strength   int    `json:"strength"`
+	Willpower  int    `json:"willpower"`
+}
+
+// Player returns the state of player 1 or 2
+func (s *GameState) Player(player int) *PlayerState {
+	if player == 2 {
+		return &s.Players[1]
+	}
+	return &s.Players[0]
+}
+
+// Opponent returns the state of the other player
+func (s *GameState) Opponent(player int) *PlayerState {
+	if player == 2 {
+		return &s.Players[0]
+	}
+	return &s.Players[1]
+}
+
+// Winner returns the player who has reached WinningLore, or 0
+func (s *GameState) Winner() int {
+	for i, player := range s.Players {
+		if player.Lore >= WinningLore {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ReadGameState reads the position after the given entries from the zones
+// and player stats lenses
+func ReadGameState(entries []parser.LogEntry, services *services.LensServices) *GameState {
+	zones := ZonesLens(entries, services).(map[string]interface{})
+	stats := PlayerStatsLens(entries, services).(map[string]interface{})
+
+	state := &GameState{
+		CurrentPlayer: getCurrentPlayer(entries),
+		Turn:          getCurrentTurn(entries),
+	}
+	for i, playerKey := range []string{"player1", "player2"} {
+		playerZones := zones[playerKey].(map[string]interface{})
+		playerStats := stats[playerKey].(map[string]interface{})
+		player := &state.Players[i]
+
+		player.Lore = statInt(playerStats["lore"])
+		player.TotalInk = statInt(playerStats["total_ink"])
+		player.AvailableInk = statInt(playerStats["available_ink"])
+		player.AvailableInkings = statInt(playerStats["available_inkings"])
+		player.HandCount = statInt(playerZones["hand_count"])
+		player.Deck = statInt(playerZones["deck"])
+		player.Discard = statInt(playerZones["discard"])
+		player.Hand = zoneCardIDs(playerZones["hand"])
+		player.Ink = zoneCardIDs(playerZones["ink"])
+
+		player.InPlay = []StateCard{}
+		inPlay, _ := playerZones["in_play"].([]interface{})
+		for _, cardInterface := range inPlay {
+			cardMap, ok := cardInterface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			card := StateCard{}
+			card.CardID, _ = cardMap["card_id"].(string)
+			card.InstanceID, _ = cardMap["instance_id"].(string)
+			card.Exhausted, _ = cardMap["exhausted"].(bool)
+			card.TurnPlayed = statInt(cardMap["turn_played"])
+			card.Dry = card.TurnPlayed != state.Turn
+			if cardData, exists := services.CardDB.GetCard(card.CardID); exists {
+				card.Type = cardData.Type
+				card.Lore = cardData.Lore
+				card.Strength = cardData.Strength
+				card.Willpower = cardData.Willpower
+			}
+			player.InPlay = append(player.InPlay, card)
+		}
+		sort.Slice(player.InPlay, func(a, b int) bool {
+			return player.InPlay[a].less(player.InPlay[b])
+		})
+	}
+	return state
+}
+
+// less orders cards in play by card, then readiness, then age
+func (c StateCard) less(other StateCard) bool {
+	if c.CardID != other.CardID {
+		return c.CardID < other.CardID
+	}
+	if c.Exhausted != other.Exhausted {
+		return !c.Exhausted
+	}
+	if c.TurnPlayed != other.TurnPlayed {
+		return c.TurnPlayed < other.TurnPlayed
+	}
+	return c.InstanceID < other.InstanceID
+}
+
+// zoneCardIDs returns the sorted card IDs of a zones lens card list
+func zoneCardIDs(zone interface{}) []string {
+	cards, _ := zone.([]interface{})
+	cardIDs := make([]string, 0, len(cards))
+	for _, cardInterface := range cards {
+		if cardMap, ok := cardInterface.(map[string]interface{}); ok {
+			if cardID, ok := cardMap["card_id"].(string); ok {
+				cardIDs = append(cardIDs, cardID)
+			}
+		}
+	}
+	sort.Strings(cardIDs)
+	return cardIDs
+}
+
+// statInt reads a lens number that may have been through JSON
+func statInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"quards/internal/i18n"
 	"quards/internal/lens/services"
 	"quards/internal/parser"
 	"strings"
@@ -20,13 +21,13 @@ type HistoryEntry struct {
 func HistoryLens(entries []parser.LogEntry, services *services.LensServices) interface{} {
 	history := make([]HistoryEntry, len(entries))
 
-	for i, entry := range entries {
+	for i, entry := range RedactEntries(entries, services.Viewer) {
 		historyEntry := HistoryEntry{
 			Step:        entry.Step,
 			Player:      entry.GetPlayer(),
 			Event:       string(entry.Event),
 			Parameters:  entry.Parameters,
-			Description: formatEventDescription(entry, services.CardDB),
+			Description: formatEventDescription(entry, services.CardDB, services.Language),
 		}
 
 		history[i] = historyEntry
@@ -35,90 +36,88 @@ func HistoryLens(entries []parser.LogEntry, services *services.LensServices) int
 	return history
 }
 
-// formatEventDescription converts a log entry into human-readable text
-func formatEventDescription(entry parser.LogEntry, cardDB services.CardDatabase) string {
-	player := entry.GetPlayer()
-	playerStr := ""
-	if player > 0 {
-		playerStr = fmt.Sprintf("Player %d ", player)
+// formatEventDescription converts a log entry into human-readable text in the given language
+func formatEventDescription(entry parser.LogEntry, cardDB services.CardDatabase, language string) string {
+	player := ""
+	if number := entry.GetPlayer(); number > 0 {
+		player = i18n.T(language, "player", i18n.Args{"player": number})
 	}
 
 	switch entry.Event {
 	case parser.GameStarted:
-		p1Deck := entry.Parameters["p1_deck"]
-		p2Deck := entry.Parameters["p2_deck"]
-		seed := entry.Parameters["seed"]
-		return fmt.Sprintf("Game starts: %s vs %s (Seed: %s)", p1Deck, p2Deck, seed)
+		if _, ok := entry.Parameters["seed"]; !ok {
+			return i18n.T(language, "history.game_started_unseeded", i18n.Args{
+				"p1_deck": entry.Parameters["p1_deck"],
+				"p2_deck": entry.Parameters["p2_deck"],
+			})
+		}
+		return i18n.T(language, "history.game_started", i18n.Args{
+			"p1_deck": entry.Parameters["p1_deck"],
+			"p2_deck": entry.Parameters["p2_deck"],
+			"seed":    entry.Parameters["seed"],
+		})
 
 	case parser.DecksShuffled:
-		seed := entry.Parameters["seed"]
-		return fmt.Sprintf("Decks shuffled (Seed: %s)", seed)
+		if _, ok := entry.Parameters["seed"]; !ok {
+			return i18n.T(language, "history.decks_shuffled_unseeded", nil)
+		}
+		return i18n.T(language, "history.decks_shuffled", i18n.Args{"seed": entry.Parameters["seed"]})
 
 	case parser.OpeningHandsDrawn:
-		return "Players draw opening hands (7 cards each)"
+		return i18n.T(language, "history.opening_hands_drawn", nil)
 
 	case parser.TurnStarted:
-		turn := entry.GetInt("turn")
-		return fmt.Sprintf("%sstarts turn %d", playerStr, turn)
+		return playerMessage(language, "history.turn_started", i18n.Args{"player": player, "turn": entry.GetInt("turn")})
 
 	case parser.CardDrawn:
 		cardID := entry.GetCard("card")
-		cardName := getCardName(cardID, cardDB)
-		return fmt.Sprintf("%sdraws %s", playerStr, cardName)
+		if cardID == "" {
+			cardID = entry.GetCard("card_id")
+		}
+		if cardID == "" {
+			return playerMessage(language, "history.card_drawn_hidden", i18n.Args{"player": player})
+		}
+		cardName := getCardName(cardID, cardDB, language)
+		return playerMessage(language, "history.card_drawn", i18n.Args{"player": player, "card": cardName})
 
 	case parser.CardInked:
-		cardID := entry.GetCard("card_id")
-		cardName := getCardName(cardID, cardDB)
-		return fmt.Sprintf("%sinks %s", playerStr, cardName)
+		cardName := getCardName(entry.GetCard("card_id"), cardDB, language)
+		return playerMessage(language, "history.card_inked", i18n.Args{"player": player, "card": cardName})
 
 	case parser.CardPlayed:
-		cardID := entry.GetCard("card_id")
-		cardName := getCardName(cardID, cardDB)
-		cost := entry.GetInt("cost")
-		costStr := ""
-		if cost > 0 {
-			costStr = fmt.Sprintf(" (%d ink)", cost)
+		cardName := getCardName(entry.GetCard("card_id"), cardDB, language)
+		if cost := entry.GetInt("cost"); cost > 0 {
+			return playerMessage(language, "history.card_played_cost", i18n.Args{"player": player, "card": cardName, "cost": cost})
 		}
-		return fmt.Sprintf("%splays %s%s", playerStr, cardName, costStr)
+		return playerMessage(language, "history.card_played", i18n.Args{"player": player, "card": cardName})
 
 	case parser.QuestAttempted:
-		cardID := entry.GetCard("card_id")
-		if cardID == "" {
-			// Try instance-based lookup
-			instanceID := entry.GetInstance("instance")
-			cardName := fmt.Sprintf("Character %s", instanceID)
-			if cardID != "" {
-				cardName = getCardName(cardID, cardDB)
-			}
-			lore := entry.GetInt("lore")
-			loreStr := ""
-			if lore > 0 {
-				loreStr = fmt.Sprintf(" (+%d lore)", lore)
-			}
-			return fmt.Sprintf("%squests with %s%s", playerStr, cardName, loreStr)
+		var cardName string
+		if cardID := entry.GetCard("card_id"); cardID != "" {
+			cardName = getCardName(cardID, cardDB, language)
+		} else {
+			// Fall back to the instance when the card isn't recorded
+			cardName = i18n.T(language, "character", i18n.Args{"instance": entry.GetInstance("instance")})
 		}
-		cardName := getCardName(cardID, cardDB)
-		lore := entry.GetInt("lore")
-		loreStr := ""
-		if lore > 0 {
-			loreStr = fmt.Sprintf(" (+%d lore)", lore)
+		if lore := entry.GetInt("lore"); lore > 0 {
+			return playerMessage(language, "history.quest_lore", i18n.Args{"player": player, "card": cardName, "lore": lore})
 		}
-		return fmt.Sprintf("%squests with %s%s", playerStr, cardName, loreStr)
+		return playerMessage(language, "history.quest", i18n.Args{"player": player, "card": cardName})
 
 	case parser.TurnPassed:
-		return fmt.Sprintf("%spasses turn", playerStr)
+		return playerMessage(language, "history.turn_passed", i18n.Args{"player": player})
+
+	case parser.HandMulliganed:
+		return playerMessage(language, "history.hand_mulliganed", i18n.Args{"player": player})
 
 	case parser.CharacterExerted:
-		instanceID := entry.GetInstance("instance")
-		return fmt.Sprintf("Character %s becomes exhausted", instanceID)
+		return i18n.T(language, "history.character_exerted", i18n.Args{"instance": entry.GetInstance("instance")})
 
 	case parser.CharacterReadied:
-		instanceID := entry.GetInstance("instance")
-		return fmt.Sprintf("Character %s becomes ready", instanceID)
+		return i18n.T(language, "history.character_readied", i18n.Args{"instance": entry.GetInstance("instance")})
 
 	case parser.CharacterBanished:
-		instanceID := entry.GetInstance("instance")
-		return fmt.Sprintf("Character %s is banished", instanceID)
+		return i18n.T(language, "history.character_banished", i18n.Args{"instance": entry.GetInstance("instance")})
 
 	default:
 		// Generic fallback for unknown events
@@ -130,19 +129,24 @@ func formatEventDescription(entry parser.LogEntry, cardDB services.CardDatabase)
 			}
 			paramStr = fmt.Sprintf(" (%s)", strings.Join(params, ", "))
 		}
-		return fmt.Sprintf("%s%s%s", playerStr, strings.ToLower(string(entry.Event)), paramStr)
+		return strings.TrimSpace(fmt.Sprintf("%s %s%s", player, strings.ToLower(string(entry.Event)), paramStr))
 	}
 }
 
-// getCardName returns the card name from the database, or the card ID as fallback
-func getCardName(cardID string, cardDB services.CardDatabase) string {
+// playerMessage formats a message that may start with an empty player name
+func playerMessage(language, key string, args i18n.Args) string {
+	return strings.TrimSpace(i18n.T(language, key, args))
+}
+
+// getCardName returns the localized card name from the database, or the card ID as fallback
+func getCardName(cardID string, cardDB services.CardDatabase, language string) string {
 	if cardID == "" {
-		return "unknown card"
+		return i18n.T(language, "unknown_card", nil)
 	}
 
 	if cardData, exists := cardDB.GetCard(cardID); exists {
-		return cardData.Name
+		return cardData.Localized(language).Name
 	}
 
 	return cardID // Fallback to ID if not found in database
-}
\ No newline at end of file
+}
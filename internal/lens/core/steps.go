@@ -9,7 +9,7 @@ import (
 func GameStepsLens(entries []parser.LogEntry, services *services.LensServices) interface{} {
 	steps := make([]map[string]interface{}, len(entries))
 
-	for i, entry := range entries {
+	for i, entry := range RedactEntries(entries, services.Viewer) {
 		steps[i] = map[string]interface{}{
 			"step":       entry.Step,
 			"player":     entry.GetPlayer(),
@@ -21,13 +21,13 @@ type NavigationStep struct {
 func StepsNavigationLens(entries []parser.LogEntry, services *services.LensServices) interface{} {
 	steps := make([]NavigationStep, len(entries))
 
-	for i, entry := range entries {
+	for i, entry := range RedactEntries(entries, services.Viewer) {
 		step := NavigationStep{
 			Step:           entry.Step,
 			Player:         entry.GetPlayer(),
 			Event:          string(entry.Event),
 			Action:         mapEventToAction(entry.Event),
-			Description:    formatEventDescription(entry, services.CardDB),
+			Description:    formatEventDescription(entry, services.CardDB, services.Language),
 			Parameters:     entry.Parameters,
 			IsPlayerChoice: isPlayerChoiceEvent(entry.Event),
 			IsFramework:    !isPlayerChoiceEvent(entry.Event),
@@ -60,6 +60,8 @@ func mapEventToAction(event parser.LogEventType) string {
 		return "quest"
 	case parser.TurnPassed:
 		return "pass"
+	case parser.HandMulliganed:
+		return "mulligan"
 	case parser.CharacterExerted:
 		return "exert_character"
 	case parser.CharacterReadied:
@@ -78,6 +80,7 @@ func isPlayerChoiceEvent(event parser.LogEventType) bool {
 		parser.CardPlayed,
 		parser.QuestAttempted,
 		parser.TurnPassed,
+		parser.HandMulliganed,
 		// Add other player choice events as needed
 	}
 
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// hiddenParameters lists the parameters of an event that only the acting
+// player may see
+var hiddenParameters = map[parser.LogEventType][]string{
+	parser.CardDrawn:      {"card", "card_id"}, // Draws appended by the server record card_id
+	parser.HandMulliganed: {"cards"},
+}
+
+// RedactEntry returns the entry as the viewer may see it. Seeds are removed
+// because they determine every library's order, and cards entering a hand
+// are removed unless the viewer owns the hand. Opening hands the viewer may
+// not see are replaced by their size, as pN_count.
+func RedactEntry(entry parser.LogEntry, viewer *services.Viewer) parser.LogEntry {
+	if viewer == nil {
+		return entry
+	}
+
+	parameters := make(map[string]string, len(entry.Parameters))
+	for key, value := range entry.Parameters {
+		parameters[key] = value
+	}
+
+	switch entry.Event {
+	case parser.GameStarted, parser.DecksShuffled:
+		delete(parameters, "seed")
+
+	case parser.OpeningHandsDrawn:
+		for player := 1; player <= 2; player++ {
+			if viewer.CanSee(player) {
+				continue
+			}
+			key := fmt.Sprintf("p%d", player)
+			parameters[key+"_count"] = strconv.Itoa(len(entry.GetStringSlice(key)))
+			delete(parameters, key)
+		}
+
+	default:
+		if !viewer.CanSee(entry.GetPlayer()) {
+			for _, key := range hiddenParameters[entry.Event] {
+				delete(parameters, key)
+			}
+		}
+	}
+
+	entry.Parameters = parameters
+	return entry
+}
+
+// RedactEntries redacts every entry for the viewer
+func RedactEntries(entries []parser.LogEntry, viewer *services.Viewer) []parser.LogEntry {
+	if viewer == nil {
+		return entries
+	}
+	redacted := make([]parser.LogEntry, len(entries))
+	for i, entry := range entries {
+		redacted[i] = RedactEntry(entry, viewer)
+	}
+	return redacted
+}
+
+// RedactZones replaces the hands the viewer may not see with an empty list,
+// leaving hand_count. The zones are copied, never modified.
+func RedactZones(zones map[string]interface{}, viewer *services.Viewer) map[string]interface{} {
+	if viewer == nil {
+		return zones
+	}
+
+	redacted := make(map[string]interface{}, len(zones))
+	for playerKey, playerZones := range zones {
+		pz, ok := playerZones.(map[string]interface{})
+		if !ok || viewer.CanSee(zonePlayer(playerKey)) {
+			redacted[playerKey] = playerZones
+			continue
+		}
+
+		hidden := make(map[string]interface{}, len(pz))
+		for zoneKey, zoneData := range pz {
+			hidden[zoneKey] = zoneData
+		}
+		hidden["hand"] = []interface{}{}
+		redacted[playerKey] = hidden
+	}
+	return redacted
+}
+
+// RedactGameState redacts the composite game state for the viewer
+func RedactGameState(state interface{}, viewer *services.Viewer) interface{} {
+	composite, ok := state.(CompositeGameState)
+	if !ok || viewer == nil {
+		return state
+	}
+	if zones, ok := composite.Zones.(map[string]interface{}); ok {
+		composite.Zones = RedactZones(zones, viewer)
+	}
+	return composite
+}
+
+// zonePlayer returns the player number of a zones key like "player2"
+func zonePlayer(playerKey string) int {
+	if playerKey == "player1" {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"quards/internal/lens/services"
+)
+
+func TestRedactEntry(t *testing.T) {
+	player1 := &services.Viewer{Player: 1}
+	player2 := &services.Viewer{Player: 2}
+	const openingHands = `OpeningHandsDrawn p1="\"CHR-001,ACT-001\"" p2="\"CHR-002,CHR-002,ACT-001\""`
+	// A visible hand is kept exactly as parsed
+	ownHand := parseLog(t, openingHands)[0].Parameters["p1"]
+
+	tests := []struct {
+		name   string
+		line   string
+		viewer *services.Viewer
+		want   map[string]string
+	}{
+		{
+			name:   "nil viewer sees everything",
+			line:   `CardDrawn card_id="CHR-001" player=2`,
+			viewer: nil,
+			want:   map[string]string{"card_id": "CHR-001", "player": "2"},
+		},
+		{
+			name:   "game seed",
+			line:   `GameStarted p1_deck="A" p2_deck="B" seed=42`,
+			viewer: player1,
+			want:   map[string]string{"p1_deck": "A", "p2_deck": "B"},
+		},
+		{
+			name:   "shuffle seed",
+			line:   `DecksShuffled seed=42`,
+			viewer: services.Spectator,
+			want:   map[string]string{},
+		},
+		{
+			name:   "caster seed",
+			line:   `GameStarted p1_deck="A" p2_deck="B" seed=42`,
+			viewer: services.Caster,
+			want:   map[string]string{"p1_deck": "A", "p2_deck": "B"},
+		},
+		{
+			name:   "caster opening hands",
+			line:   openingHands,
+			viewer: services.Caster,
+			want:   parseLog(t, openingHands)[0].Parameters,
+		},
+		{
+			name:   "own opening hand",
+			line:   openingHands,
+			viewer: player1,
+			want:   map[string]string{"p1": ownHand, "p2_count": "3"},
+		},
+		{
+			name:   "spectator opening hands",
+			line:   openingHands,
+			viewer: services.Spectator,
+			want:   map[string]string{"p1_count": "2", "p2_count": "3"},
+		},
+		{
+			name:   "opponent's server-appended draw",
+			line:   `CardDrawn card_id="CHR-001" player=2`,
+			viewer: player1,
+			want:   map[string]string{"player": "2"},
+		},
+		{
+			name:   "opponent's draw",
+			line:   `CardDrawn card="CHR-001" player=2`,
+			viewer: player1,
+			want:   map[string]string{"player": "2"},
+		},
+		{
+			name:   "own draw",
+			line:   `CardDrawn card_id="CHR-001" player=2`,
+			viewer: player2,
+			want:   map[string]string{"card_id": "CHR-001", "player": "2"},
+		},
+		{
+			name:   "caster sees draws",
+			line:   `CardDrawn card_id="CHR-001" player=2`,
+			viewer: services.Caster,
+			want:   map[string]string{"card_id": "CHR-001", "player": "2"},
+		},
+		{
+			name:   "opponent's mulligan",
+			line:   `HandMulliganed cards="CHR-001,ACT-001" player=2`,
+			viewer: player1,
+			want:   map[string]string{"player": "2"},
+		},
+		{
+			name:   "own mulligan",
+			line:   `HandMulliganed cards="CHR-001,ACT-001" player=2`,
+			viewer: player2,
+			want:   map[string]string{"cards": "CHR-001,ACT-001", "player": "2"},
+		},
+		{
+			name:   "played cards are public",
+			line:   `CardPlayed card_id="CHR-001" instance="$CHAR_001" player=2`,
+			viewer: services.Spectator,
+			want:   map[string]string{"card_id": "CHR-001", "instance": "$CHAR_001", "player": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := parseLog(t, tt.line)[0]
+			original := make(map[string]string, len(entry.Parameters))
+			for key, value := range entry.Parameters {
+				original[key] = value
+			}
+
+			got := RedactEntry(entry, tt.viewer).Parameters
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parameters = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(entry.Parameters, original) {
+				t.Errorf("original entry modified to %v", entry.Parameters)
+			}
+		})
+	}
+}
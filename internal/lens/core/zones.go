@@ -23,7 +23,8 @@ type InkCard struct {
 	CardID string `json:"card_id"`
 }
 
-// ZonesLens computes zones state from event-sourcing log format
+// ZonesLens computes zones state from event-sourcing log format. Hands the
+// viewer may not see are left empty; hand_count always has their size.
 func ZonesLens(entries []parser.LogEntry, services *services.LensServices) interface{} {
 	zones := map[string]interface{}{
 		"player1": map[string]interface{}{
@@ -85,7 +86,11 @@ func ZonesLens(entries []parser.LogEntry, services *services.LensServices) inter
 			player2Zones["deck"] = p2DeckCount - len(p2Cards)
 
 		case parser.CardDrawn:
+			// Draws appended by the server name the card as card_id
 			cardID := entry.GetCard("card")
+			if cardID == "" {
+				cardID = entry.GetCard("card_id")
+			}
 			player := entry.GetPlayer()
 			playerKey := getPlayerZoneKey(player)
 			
@@ -109,14 +114,7 @@ func ZonesLens(entries []parser.LogEntry, services *services.LensServices) inter
 			
 			if playerZones, ok := zones[playerKey].(map[string]interface{}); ok {
 				// Remove from hand
-				hand := playerZones["hand"].([]HandCard)
-				newHand := make([]HandCard, 0, len(hand))
-				for _, card := range hand {
-					if card.CardID != cardID {
-						newHand = append(newHand, card)
-					}
-				}
-				playerZones["hand"] = newHand
+				playerZones["hand"] = removeHandCard(playerZones["hand"].([]HandCard), cardID)
 
 				// Check card type to determine destination
 				if cardData, exists := services.CardDB.GetCard(cardID); exists {
@@ -155,14 +153,7 @@ func ZonesLens(entries []parser.LogEntry, services *services.LensServices) inter
 			
 			if playerZones, ok := zones[playerKey].(map[string]interface{}); ok {
 				// Remove from hand
-				hand := playerZones["hand"].([]HandCard)
-				newHand := make([]HandCard, 0, len(hand))
-				for _, card := range hand {
-					if card.CardID != cardID {
-						newHand = append(newHand, card)
-					}
-				}
-				playerZones["hand"] = newHand
+				playerZones["hand"] = removeHandCard(playerZones["hand"].([]HandCard), cardID)
 
 				// Add to ink
 				ink := playerZones["ink"].([]InkCard)
@@ -273,6 +264,7 @@ func ZonesLens(entries []parser.LogEntry, services *services.LensServices) inter
 					}
 				}
 				convertedZones[zoneKey] = handInterface
+				convertedZones["hand_count"] = len(handCards)
 			case "in_play":
 				inPlayCards := zoneData.([]InPlayCard)
 				inPlayInterface := make([]interface{}, len(inPlayCards))
@@ -301,7 +293,7 @@ func ZonesLens(entries []parser.LogEntry, services *services.LensServices) inter
 		result[playerKey] = convertedZones
 	}
 	
-	return result
+	return RedactZones(result, services.Viewer)
 }
 
 // Helper function to get the correct player zone key
@@ -310,4 +302,16 @@ func getPlayerZoneKey(player int) string {
 		return "player1"
 	}
 	return "player2"
-}
\ No newline at end of file
+}
+
+// removeHandCard removes one copy of a card from a hand
+func removeHandCard(hand []HandCard, cardID string) []HandCard {
+	for i, card := range hand {
+		if card.CardID == cardID {
+			newHand := make([]HandCard, 0, len(hand)-1)
+			newHand = append(newHand, hand[:i]...)
+			return append(newHand, hand[i+1:]...)
+		}
+	}
+	return hand
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// testServices returns lens services over a few made-up cards
+func testServices(t *testing.T) *services.LensServices {
+	t.Helper()
+	cardDB, err := services.NewOverlayCardDB(services.NewInMemoryCardDB(), map[string]services.CardPatch{
+		"CHR-001": {"Name": "Test Hero", "Type": "Character", "Color": "Amber", "Cost": 1, "Lore": 1, "Inkable": true},
+		"CHR-002": {"Name": "Test Villain", "Type": "Character", "Color": "Amber", "Cost": 2, "Lore": 2, "Inkable": true},
+		"ACT-001": {"Name": "Test Song", "Type": "Action", "Color": "Amber", "Cost": 1, "Inkable": true},
+	})
+	if err != nil {
+		t.Fatalf("NewOverlayCardDB: %v", err)
+	}
+	return &services.LensServices{CardDB: cardDB}
+}
+
+func parseLog(t *testing.T, content string) []parser.LogEntry {
+	t.Helper()
+	entries, err := parser.ParseLogContent(content)
+	if err != nil {
+		t.Fatalf("ParseLogContent: %v", err)
+	}
+	return entries
+}
+
+// zoneCards returns the card IDs in one of a player's zones
+func zoneCards(zones map[string]interface{}, playerKey, zone string) []string {
+	cards := zones[playerKey].(map[string]interface{})[zone].([]interface{})
+	cardIDs := make([]string, len(cards))
+	for i, card := range cards {
+		cardIDs[i] = card.(map[string]interface{})["card_id"].(string)
+	}
+	return cardIDs
+}
+
+const setup = `GameStarted p1_deck="A" p2_deck="B" seed=1
+DecksShuffled seed=1
+OpeningHandsDrawn p1="\"CHR-001,CHR-001,ACT-001,CHR-002,CHR-002,CHR-002,CHR-002\"" p2="\"ACT-001,ACT-001,ACT-001,ACT-001,CHR-002,CHR-002,CHR-002\""
+TurnStarted player=1 turn=1
+`
+
+// Logs written before played cards were given instances must replay the same
+func TestZonesLensReplaysLegacyLog(t *testing.T) {
+	entries := parseLog(t, setup+`CardInked card_id="CHR-002" player=1
+CardPlayed card_id="CHR-001" player=1
+TurnPassed player=1
+CardDrawn card_id="CHR-001" player=2
+TurnStarted player=2 turn=2
+`)
+	zones := ZonesLens(entries, testServices(t)).(map[string]interface{})
+
+	tests := []struct {
+		player, zone string
+		want         []string
+	}{
+		// Playing or inking one copy leaves the other copies in hand
+		{"player1", "hand", []string{"CHR-001", "ACT-001", "CHR-002", "CHR-002", "CHR-002"}},
+		{"player1", "ink", []string{"CHR-002"}},
+		{"player1", "in_play", []string{"CHR-001"}},
+		// Draws appended by the server name the card as card_id
+		{"player2", "hand", []string{"ACT-001", "ACT-001", "ACT-001", "ACT-001", "CHR-002", "CHR-002", "CHR-002", "CHR-001"}},
+	}
+	for _, tt := range tests {
+		if got := zoneCards(zones, tt.player, tt.zone); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s = %v, want %v", tt.player, tt.zone, got, tt.want)
+		}
+	}
+
+	if deck := zones["player2"].(map[string]interface{})["deck"]; deck != 52 {
+		t.Errorf("player2 deck = %v, want 52", deck)
+	}
+}
+
+// Quests name the instance, so only that copy of a character is exerted
+func TestZonesLensQuestExertsInstance(t *testing.T) {
+	entries := parseLog(t, setup+`CardPlayed card_id="CHR-001" instance="$CHAR_001" player=1
+TurnPassed player=1
+TurnStarted player=2 turn=2
+TurnPassed player=2
+TurnStarted player=1 turn=3
+CardPlayed card_id="CHR-001" instance="$CHAR_002" player=1
+QuestAttempted card_id="CHR-001" instance="$CHAR_001" player=1
+`)
+	zones := ZonesLens(entries, testServices(t)).(map[string]interface{})
+
+	inPlay := zones["player1"].(map[string]interface{})["in_play"].([]interface{})
+	exhausted := map[string]bool{}
+	for _, card := range inPlay {
+		cardMap := card.(map[string]interface{})
+		exhausted[cardMap["instance_id"].(string)] = cardMap["exhausted"].(bool)
+	}
+	want := map[string]bool{"$CHAR_001": true, "$CHAR_002": false}
+	if !reflect.DeepEqual(exhausted, want) {
+		t.Errorf("exhausted = %v, want %v", exhausted, want)
+	}
+}
@@ -2,6 +2,9 @@ package lens
 
 import (
 	"fmt"
+	"log"
+	"sync"
+
 	"quards/internal/lens/core"
 	"quards/internal/lens/services"
 	"quards/internal/parser"
@@ -16,12 +19,31 @@ type Processor struct {
 	lenses   map[string]LensFunc
 }
 
-// New creates a lens processor with default services
+var (
+	defaultCardDB services.CardDatabase
+	defaultMutex  sync.RWMutex
+)
+
+// SetDefaultCardDB sets the card database shared by processors created with New
+func SetDefaultCardDB(cardDB services.CardDatabase) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultCardDB = cardDB
+}
+
+// New creates a lens processor with default services. Without a default card
+// database, cards are loaded from the canonical card file.
 func New() *Processor {
-	cardDB := services.NewInMemoryCardDB()
-	if err := cardDB.LoadFromFile("static/cards.lorcana-api.json"); err != nil {
-		// In production, you'd want proper error handling here
-		_ = err
+	defaultMutex.RLock()
+	cardDB := defaultCardDB
+	defaultMutex.RUnlock()
+
+	if cardDB == nil {
+		fileDB := services.NewInMemoryCardDB()
+		if err := fileDB.LoadFromFile(services.CardsPath()); err != nil {
+			log.Printf("lens: %v", err)
+		}
+		cardDB = fileDB
 	}
 
 	return NewWithServices(&services.LensServices{
@@ -48,6 +70,28 @@ func NewWithServices(svc *services.LensServices) *Processor {
 	}
 }
 
+// WithLanguage returns a processor sharing this one's lenses and services
+// whose lenses produce text in the given language
+func (p *Processor) WithLanguage(language string) *Processor {
+	svc := *p.services
+	svc.Language = language
+	return &Processor{
+		services: &svc,
+		lenses:   p.lenses,
+	}
+}
+
+// WithViewer returns a processor sharing this one's lenses and services
+// whose lenses hide what the viewer may not see
+func (p *Processor) WithViewer(viewer *services.Viewer) *Processor {
+	svc := *p.services
+	svc.Viewer = viewer
+	return &Processor{
+		services: &svc,
+		lenses:   p.lenses,
+	}
+}
+
 // Lens executes a lens by name
 func (p *Processor) Lens(name string, entries []parser.LogEntry) (interface{}, error) {
 	lensFunc, exists := p.lenses[name]
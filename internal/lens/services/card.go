@@ -0,0 +1,104 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Normalize fills in the parsed fields from the raw card data
+func (c *CardData) Normalize() {
+	c.Colors = splitList(c.Color)
+	c.ClassificationList = splitList(c.Classifications)
+	c.Keywords = ParseKeywords(c.Abilities)
+}
+
+// Localized returns a copy of the card with its name and text in the given
+// language, or the card itself if it has no translation for that language
+func (c *CardData) Localized(language string) *CardData {
+	translation, exists := c.Translations[language]
+	if !exists || translation.Name == "" {
+		return c
+	}
+
+	localized := *c
+	localized.Language = language
+	localized.Name = translation.Name
+	if translation.BodyText != "" {
+		localized.BodyText = translation.BodyText
+	}
+	if translation.FlavorText != "" {
+		localized.FlavorText = translation.FlavorText
+	}
+	return &localized
+}
+
+// BaseType returns the card type without its subtype, e.g. "Action" for "Action - Song"
+func (c *CardData) BaseType() string {
+	baseType, _, _ := strings.Cut(c.Type, " - ")
+	return baseType
+}
+
+// Subtype returns the card's subtype, e.g. "Song" for "Action - Song"
+func (c *CardData) Subtype() string {
+	_, subtype, _ := strings.Cut(c.Type, " - ")
+	return subtype
+}
+
+// HasColor reports whether the card is of the given ink color
+func (c *CardData) HasColor(color string) bool {
+	return containsFold(c.Colors, color)
+}
+
+// HasClassification reports whether the card has the given classification
+func (c *CardData) HasClassification(classification string) bool {
+	return containsFold(c.ClassificationList, classification)
+}
+
+// HasKeyword reports whether the card has the given keyword ability
+func (c *CardData) HasKeyword(keyword string) bool {
+	return containsFold(c.Keywords, keyword)
+}
+
+// ParseKeywords splits a card's abilities field into keyword names, dropping
+// values such as the "+2" in "Challenger +2" or the "5" in "Shift 5"
+func ParseKeywords(abilities string) []string {
+	keywords := []string{}
+	seen := make(map[string]bool)
+
+	for _, ability := range strings.Split(abilities, ",") {
+		words := strings.Fields(ability)
+		for len(words) > 0 && strings.IndexFunc(words[len(words)-1], unicode.IsLetter) == -1 {
+			words = words[:len(words)-1]
+		}
+		keyword := strings.Join(words, " ")
+		if keyword != "" && !seen[keyword] {
+			seen[keyword] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	sort.Strings(keywords)
+	return keywords
+}
+
+// splitList splits a comma-separated field into trimmed, non-empty values
+func splitList(value string) []string {
+	values := []string{}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// containsFold reports whether values contains target, ignoring case
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,9 +7,22 @@ import (
 	"sync"
 )
 
+// DefaultCardsPath is the canonical card file written by cmd/cards
+const DefaultCardsPath = "data/cards.json"
+
+// CardsPath returns the card file to load, overridable with CARDS_PATH
+func CardsPath() string {
+	if path := os.Getenv("CARDS_PATH"); path != "" {
+		return path
+	}
+	return DefaultCardsPath
+}
+
 // InMemoryCardDB implements CardDatabase interface with in-memory storage
 type InMemoryCardDB struct {
 	cards map[string]*CardData
+	index *CardIndex
+	path  string
 	mutex sync.RWMutex
 	loaded bool
 }
@@ -18,6 +31,7 @@ type InMemoryCardDB struct {
 func NewInMemoryCardDB() *InMemoryCardDB {
 	return &InMemoryCardDB{
 		cards: make(map[string]*CardData),
+		index: NewCardIndex(nil),
 	}
 }
 
@@ -38,17 +52,36 @@ func (db *InMemoryCardDB) LoadFromFile(filePath string) error {
 		return fmt.Errorf("failed to decode card database: %w", err)
 	}
 
+	if len(cards) == 0 {
+		return fmt.Errorf("card database file %s contains no cards", filePath)
+	}
+
 	// Clear existing cards and load new ones
 	db.cards = make(map[string]*CardData)
 	for i := range cards {
 		card := &cards[i]
+		card.Normalize()
 		db.cards[card.UniqueID] = card
 	}
+	db.index = NewCardIndex(db.cards)
+	db.path = filePath
 
 	db.loaded = true
 	return nil
 }
 
+// Reload re-reads the file the database was last loaded from
+func (db *InMemoryCardDB) Reload() error {
+	db.mutex.RLock()
+	path := db.path
+	db.mutex.RUnlock()
+
+	if path == "" {
+		return fmt.Errorf("card database was not loaded from a file")
+	}
+	return db.LoadFromFile(path)
+}
+
 // GetCard retrieves a card by its unique ID
 func (db *InMemoryCardDB) GetCard(id string) (*CardData, bool) {
 	db.mutex.RLock()
@@ -71,6 +104,13 @@ func (db *InMemoryCardDB) GetAll() map[string]*CardData {
 	return result
 }
 
+// Search returns the cards matching a query using the index built at load
+func (db *InMemoryCardDB) Search(query CardQuery) *CardSearchResult {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	return db.index.Search(query)
+}
+
 // IsLoaded returns whether the database has been loaded
 func (db *InMemoryCardDB) IsLoaded() bool {
 	db.mutex.RLock()
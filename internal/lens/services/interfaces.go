@@ -4,6 +4,15 @@ package services
 type CardDatabase interface {
 	GetCard(id string) (*CardData, bool)
 	GetAll() map[string]*CardData
+	Search(query CardQuery) *CardSearchResult
+}
+
+// ReloadableCardDatabase is a card database whose contents can be refreshed
+// from its source without a restart
+type ReloadableCardDatabase interface {
+	CardDatabase
+	Reload() error
+	Count() int
 }
 
 // CacheService provides caching functionality for lens computations
@@ -16,25 +25,53 @@ type CacheService interface {
 
 // LensServices groups all service dependencies for lens functions
 type LensServices struct {
-	CardDB CardDatabase
-	Cache  CacheService
+	CardDB   CardDatabase
+	Cache    CacheService
+	Language string  // Language for card names and descriptions; empty means English
+	Viewer   *Viewer // Who the output is for; nil sees everything
 }
 
-// CardData represents card information from the database
+// CardData represents card information from the database. The raw
+// comma-separated fields are kept as loaded; Colors, ClassificationList and
+// Keywords are parsed from them by Normalize.
 type CardData struct {
-	UniqueID    string `json:"Unique_ID"`
-	Name        string `json:"Name"`
-	Title       string `json:"Title"`
-	Color       string `json:"Color"`
-	Cost        int    `json:"Cost"`
-	Inkable     bool   `json:"Inkable"`
-	Type        string `json:"Type"`
-	Lore        int    `json:"Lore"`
-	Willpower   int    `json:"Willpower"`
-	Strength    int    `json:"Strength"`
-	Image       string `json:"Image"`
-	Illustrator string `json:"Illustrator"`
-	Language    string `json:"Language"`
-	Set         string `json:"Set"`
+	UniqueID        string `json:"Unique_ID"`
+	Name            string `json:"Name"`
+	Title           string `json:"Title"`
+	Color           string `json:"Color"` // "Amber, Steel" for dual-ink cards
+	Cost            int    `json:"Cost"`
+	Inkable         bool   `json:"Inkable"`
+	Type            string `json:"Type"`
+	Lore            int    `json:"Lore"`
+	Willpower       int    `json:"Willpower"`
+	Strength        int    `json:"Strength"`
+	MoveCost        int    `json:"Move_Cost,omitempty"` // Locations only
+	Image           string `json:"Image"`
+	Illustrator     string `json:"Illustrator"`
+	Artist          string `json:"Artist"`
+	Language        string `json:"Language"`
+	Set             string `json:"Set"`
+	SetNum          int    `json:"Set_Num"`
+	SetID           string `json:"Set_ID"`
+	SetName         string `json:"Set_Name"`
+	CardNum         int    `json:"Card_Num"`
+	Rarity          string `json:"Rarity"`
+	Franchise       string `json:"Franchise"`
+	Classifications string `json:"Classifications"` // e.g. "Storyborn, Hero, Princess"
+	Abilities       string `json:"Abilities"`       // Comma-separated keywords, e.g. "Shift, Evasive"
+	BodyText        string `json:"Body_Text"`
+	FlavorText      string `json:"Flavor_Text"`
+
+	Colors             []string `json:"Colors"`
+	ClassificationList []string `json:"Classification_List"`
+	Keywords           []string `json:"Keywords"`
+
+	Translations map[string]CardTranslation `json:"Translations,omitempty"` // Language -> localized text
 }
 
+// CardTranslation holds the localized text of a card in one language
+type CardTranslation struct {
+	Name       string `json:"Name"`
+	BodyText   string `json:"Body_Text,omitempty"`
+	FlavorText string `json:"Flavor_Text,omitempty"`
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CardPatch holds card fields to add or override, keyed by their card JSON
+// names (e.g. "Cost", "Body_Text"). A null value clears the field.
+type CardPatch map[string]interface{}
+
+// OverlayCardDB applies card patches on top of a base card database. Patched
+// and added cards are merged once at construction; all other lookups fall
+// through to the base.
+type OverlayCardDB struct {
+	base    CardDatabase
+	patched map[string]*CardData
+	index   *CardIndex
+}
+
+// NewOverlayCardDB merges patches into the base card database. Patches for
+// IDs missing from the base add new cards and must define Name, Type and Color.
+func NewOverlayCardDB(base CardDatabase, patches map[string]CardPatch) (*OverlayCardDB, error) {
+	overlay := &OverlayCardDB{
+		base:    base,
+		patched: make(map[string]*CardData, len(patches)),
+	}
+
+	ids := make([]string, 0, len(patches))
+	for id := range patches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		card, err := ApplyPatch(base, id, patches[id])
+		if err != nil {
+			return nil, err
+		}
+		overlay.patched[id] = card
+	}
+
+	overlay.index = NewCardIndex(overlay.GetAll())
+	return overlay, nil
+}
+
+// ApplyPatch returns the card with the given ID after applying a patch
+func ApplyPatch(base CardDatabase, id string, patch CardPatch) (*CardData, error) {
+	fields := make(map[string]interface{})
+
+	baseCard, exists := base.GetCard(id)
+	if exists {
+		data, err := json.Marshal(baseCard)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(fields, key)
+		} else {
+			fields[key] = value
+		}
+	}
+	fields["Unique_ID"] = id
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("card %s: %w", id, err)
+	}
+	var card CardData
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("card %s: invalid field: %w", id, err)
+	}
+	card.Normalize()
+
+	if !exists {
+		if card.Name == "" || card.Type == "" || len(card.Colors) == 0 {
+			return nil, fmt.Errorf("card %s: new cards must define Name, Type and Color", id)
+		}
+	}
+
+	return &card, nil
+}
+
+// GetCard retrieves a card by its unique ID, preferring the overlay's version
+func (o *OverlayCardDB) GetCard(id string) (*CardData, bool) {
+	if card, exists := o.patched[id]; exists {
+		return card, true
+	}
+	return o.base.GetCard(id)
+}
+
+// GetAll returns the base cards with the overlay applied
+func (o *OverlayCardDB) GetAll() map[string]*CardData {
+	result := o.base.GetAll()
+	for id, card := range o.patched {
+		result[id] = card
+	}
+	return result
+}
+
+// Search returns the cards matching a query, including patched and added cards
+func (o *OverlayCardDB) Search(query CardQuery) *CardSearchResult {
+	return o.index.Search(query)
+}
+
+// Patched returns the IDs of the cards the overlay changes or adds
+func (o *OverlayCardDB) Patched() []string {
+	ids := make([]string, 0, len(o.patched))
+	for id := range o.patched {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
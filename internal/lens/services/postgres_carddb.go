@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CardsChangedChannel is notified by the cards table trigger on every change
+const CardsChangedChannel = "cards_changed"
+
+// PostgresCardDB implements CardDatabase on top of the cards table. All cards
+// are cached in memory on Reload; lookups for cards added since then read
+// through to the database and join the cache and search index, while unknown
+// IDs are remembered until the next reload.
+type PostgresCardDB struct {
+	db      *sql.DB
+	cards   map[string]*CardData
+	missing map[string]bool // IDs not in the cards table at their last lookup
+	index   *CardIndex
+	mutex   sync.RWMutex
+	loaded  bool
+}
+
+// NewPostgresCardDB creates a card database backed by the cards table
+func NewPostgresCardDB(db *sql.DB) *PostgresCardDB {
+	return &PostgresCardDB{
+		db:      db,
+		cards:   make(map[string]*CardData),
+		missing: make(map[string]bool),
+		index:   NewCardIndex(nil),
+	}
+}
+
+// Reload replaces the cache with the current contents of the cards table
+func (p *PostgresCardDB) Reload() error {
+	rows, err := p.db.Query("SELECT data FROM cards")
+	if err != nil {
+		return fmt.Errorf("failed to query cards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := make(map[string]*CardData)
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return err
+		}
+		cards[card.UniqueID] = card
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read cards: %w", err)
+	}
+
+	index := NewCardIndex(cards)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cards = cards
+	p.missing = make(map[string]bool)
+	p.index = index
+	p.loaded = true
+	return nil
+}
+
+// GetCard retrieves a card by its unique ID, falling back to the database on
+// a cache miss. Cards found that way are added to the search index.
+func (p *PostgresCardDB) GetCard(id string) (*CardData, bool) {
+	p.mutex.RLock()
+	card, exists := p.cards[id]
+	missing := p.missing[id]
+	p.mutex.RUnlock()
+	if exists {
+		return card, true
+	}
+	if missing {
+		return nil, false
+	}
+
+	card, err := scanCard(p.db.QueryRow("SELECT data FROM cards WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		p.mutex.Lock()
+		p.missing[id] = true
+		p.mutex.Unlock()
+		return nil, false
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	// Cards are only added between reloads when the listener lags behind,
+	// so rebuilding the index is rare
+	p.mutex.Lock()
+	p.cards[id] = card
+	p.index = NewCardIndex(p.cards)
+	p.mutex.Unlock()
+	return card, true
+}
+
+// GetAll returns all cached cards
+func (p *PostgresCardDB) GetAll() map[string]*CardData {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	result := make(map[string]*CardData, len(p.cards))
+	for k, v := range p.cards {
+		result[k] = v
+	}
+	return result
+}
+
+// Search returns the cards matching a query, including those read through since the last reload
+func (p *PostgresCardDB) Search(query CardQuery) *CardSearchResult {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.index.Search(query)
+}
+
+// IsLoaded returns whether the cache has been loaded
+func (p *PostgresCardDB) IsLoaded() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.loaded
+}
+
+// Count returns the number of cached cards
+func (p *PostgresCardDB) Count() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return len(p.cards)
+}
+
+// Listen reloads the cache whenever the cards table changes, until ctx is
+// cancelled. onReload, if set, is called after each reload attempt.
+func (p *PostgresCardDB) Listen(ctx context.Context, dbURL string, onReload func(error)) error {
+	listener := pq.NewListener(dbURL, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("card listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(CardsChangedChannel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", CardsChangedChannel, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-listener.Notify:
+			// A nil notification means the connection was re-established and
+			// changes may have been missed, so reload either way
+			err := p.Reload()
+			if onReload != nil {
+				onReload(err)
+			}
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+func scanCard(row interface{ Scan(dest ...interface{}) error }) (*CardData, error) {
+	var data []byte
+	if err := row.Scan(&data); err != nil {
+		return nil, err
+	}
+
+	var card CardData
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("failed to decode card: %w", err)
+	}
+	card.Normalize()
+	return &card, nil
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Sort orders for card searches
+const (
+	SortByName = "name"
+	SortByCost = "cost"
+	SortBySet  = "set" // Set number, then card number
+	SortByID   = "id"
+)
+
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 500
+)
+
+// CardQuery filters, sorts and pages a card search. Empty fields match any
+// card; list fields match cards with any of the listed values.
+type CardQuery struct {
+	Text            string // Words matched as prefixes against name and body text
+	Colors          []string
+	MinCost         *int
+	MaxCost         *int
+	Types           []string // Base type or full type, e.g. "Action" or "Action - Song"
+	Inkable         *bool
+	Sets            []string // Set number or Set_ID
+	Rarities        []string
+	Classifications []string
+	Keywords        []string
+	Sort            string
+	Descending      bool
+	Offset          int
+	Limit           int
+}
+
+// CardSearchResult is one page of matching cards
+type CardSearchResult struct {
+	Cards  []*CardData `json:"cards"`
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+}
+
+// CardIndex holds precomputed lookups over a card set so searches avoid
+// rescanning card text
+type CardIndex struct {
+	cards      []*CardData      // Sorted by ID
+	words      []string         // Sorted vocabulary of name and body text in every language
+	postings   map[string][]int // Word -> card positions
+	attributes map[string]map[string][]int
+}
+
+// Attribute names used for exact-match filters
+const (
+	attrColor          = "color"
+	attrType           = "type"
+	attrSet            = "set"
+	attrRarity         = "rarity"
+	attrClassification = "classification"
+	attrKeyword        = "keyword"
+)
+
+// NewCardIndex builds an index over the given cards
+func NewCardIndex(cards map[string]*CardData) *CardIndex {
+	index := &CardIndex{
+		cards:      make([]*CardData, 0, len(cards)),
+		postings:   make(map[string][]int),
+		attributes: make(map[string]map[string][]int),
+	}
+
+	for _, card := range cards {
+		index.cards = append(index.cards, card)
+	}
+	sort.Slice(index.cards, func(i, j int) bool {
+		return index.cards[i].UniqueID < index.cards[j].UniqueID
+	})
+
+	for position, card := range index.cards {
+		seen := make(map[string]bool)
+		text := card.Name + " " + card.BodyText
+		for _, translation := range card.Translations {
+			text += " " + translation.Name + " " + translation.BodyText
+		}
+		for _, word := range tokenize(text) {
+			if !seen[word] {
+				seen[word] = true
+				index.postings[word] = append(index.postings[word], position)
+			}
+		}
+
+		for _, color := range card.Colors {
+			index.addAttribute(attrColor, color, position)
+		}
+		index.addAttribute(attrType, card.Type, position)
+		if baseType := card.BaseType(); baseType != card.Type {
+			index.addAttribute(attrType, baseType, position)
+		}
+		if card.SetNum > 0 {
+			index.addAttribute(attrSet, strconv.Itoa(card.SetNum), position)
+		}
+		if card.SetID != "" {
+			index.addAttribute(attrSet, card.SetID, position)
+		}
+		index.addAttribute(attrRarity, card.Rarity, position)
+		for _, classification := range card.ClassificationList {
+			index.addAttribute(attrClassification, classification, position)
+		}
+		for _, keyword := range card.Keywords {
+			index.addAttribute(attrKeyword, keyword, position)
+		}
+	}
+
+	index.words = make([]string, 0, len(index.postings))
+	for word := range index.postings {
+		index.words = append(index.words, word)
+	}
+	sort.Strings(index.words)
+
+	return index
+}
+
+func (idx *CardIndex) addAttribute(attribute, value string, position int) {
+	if value == "" {
+		return
+	}
+	values, exists := idx.attributes[attribute]
+	if !exists {
+		values = make(map[string][]int)
+		idx.attributes[attribute] = values
+	}
+	key := strings.ToLower(value)
+	values[key] = append(values[key], position)
+}
+
+// Search returns the page of cards matching the query
+func (idx *CardIndex) Search(query CardQuery) *CardSearchResult {
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	if query.Limit > MaxSearchLimit {
+		query.Limit = MaxSearchLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	// nil means "all cards"; each filter narrows the candidate set
+	var candidates map[int]bool
+	narrow := func(positions map[int]bool) {
+		if candidates == nil {
+			candidates = positions
+			return
+		}
+		for position := range candidates {
+			if !positions[position] {
+				delete(candidates, position)
+			}
+		}
+	}
+
+	for _, word := range tokenize(query.Text) {
+		narrow(idx.matchPrefix(word))
+	}
+	filters := []struct {
+		attribute string
+		values    []string
+	}{
+		{attrColor, query.Colors},
+		{attrType, query.Types},
+		{attrSet, query.Sets},
+		{attrRarity, query.Rarities},
+		{attrClassification, query.Classifications},
+		{attrKeyword, query.Keywords},
+	}
+	for _, filter := range filters {
+		if len(filter.values) > 0 {
+			narrow(idx.matchAttribute(filter.attribute, filter.values))
+		}
+	}
+
+	matches := []*CardData{}
+	for position, card := range idx.cards {
+		if candidates != nil && !candidates[position] {
+			continue
+		}
+		if query.MinCost != nil && card.Cost < *query.MinCost {
+			continue
+		}
+		if query.MaxCost != nil && card.Cost > *query.MaxCost {
+			continue
+		}
+		if query.Inkable != nil && card.Inkable != *query.Inkable {
+			continue
+		}
+		matches = append(matches, card)
+	}
+
+	sortCards(matches, query.Sort, query.Descending)
+
+	result := &CardSearchResult{
+		Cards:  []*CardData{},
+		Total:  len(matches),
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	}
+	if query.Offset < len(matches) {
+		end := min(query.Offset+query.Limit, len(matches))
+		result.Cards = matches[query.Offset:end]
+	}
+	return result
+}
+
+// matchPrefix returns the positions of cards containing a word starting with prefix
+func (idx *CardIndex) matchPrefix(prefix string) map[int]bool {
+	positions := make(map[int]bool)
+	start := sort.SearchStrings(idx.words, prefix)
+	for i := start; i < len(idx.words) && strings.HasPrefix(idx.words[i], prefix); i++ {
+		for _, position := range idx.postings[idx.words[i]] {
+			positions[position] = true
+		}
+	}
+	return positions
+}
+
+// matchAttribute returns the positions of cards with any of the attribute values
+func (idx *CardIndex) matchAttribute(attribute string, values []string) map[int]bool {
+	positions := make(map[int]bool)
+	for _, value := range values {
+		for _, position := range idx.attributes[attribute][strings.ToLower(strings.TrimSpace(value))] {
+			positions[position] = true
+		}
+	}
+	return positions
+}
+
+// sortCards orders cards in place; ties fall back to card ID
+func sortCards(cards []*CardData, order string, descending bool) {
+	less := func(a, b *CardData) bool {
+		switch order {
+		case SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case SortByCost:
+			if a.Cost != b.Cost {
+				return a.Cost < b.Cost
+			}
+		case SortBySet:
+			if a.SetNum != b.SetNum {
+				return a.SetNum < b.SetNum
+			}
+			if a.CardNum != b.CardNum {
+				return a.CardNum < b.CardNum
+			}
+		}
+		return a.UniqueID < b.UniqueID
+	}
+
+	sort.SliceStable(cards, func(i, j int) bool {
+		if descending {
+			return less(cards[j], cards[i])
+		}
+		return less(cards[i], cards[j])
+	})
+}
+
+// tokenize lowercases text and splits it into words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,23 @@
+package services
+
+// Viewer describes whose point of view lens output is computed from. Hidden
+// information, such as a player's hand and the order of their library, is
+// only visible to that player until the game is over.
+type Viewer struct {
+	Player int  // Seat the viewer plays, 0 for spectators
+	Caster bool // Sees both hands, but not the seed
+}
+
+// Spectator is a viewer who sees no hidden information
+var Spectator = &Viewer{}
+
+// Caster is a spectator who sees both hands. The seed stays hidden, as it
+// would reveal every future draw.
+var Caster = &Viewer{Caster: true}
+
+// CanSee reports whether the viewer may see the given player's hidden
+// information. A nil viewer sees everything.
+func (v *Viewer) CanSee(player int) bool {
+	return v == nil || v.Caster || (v.Player > 0 && v.Player == player)
+}
+
@@ -0,0 +1,293 @@
+package overlay
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"quards/internal/database"
+	"quards/internal/lens/services"
+)
+
+// Overlay is one immutable version of a named set of card patches. A personal
+// overlay belongs to a user (custom or playtest cards); a format overlay
+// carries errata for a format.
+type Overlay struct {
+	ID          int                           `json:"id"`
+	Name        string                        `json:"name"`
+	Version     int                           `json:"version"`
+	Description string                        `json:"description"`
+	UserID      *int                          `json:"userId,omitempty"`
+	Format      string                        `json:"format,omitempty"`
+	Patches     map[string]services.CardPatch `json:"patches"` // Unique_ID -> fields to add or override
+	Created     time.Time                     `json:"created"`
+}
+
+// OverlayList represents a simplified overlay for listing
+type OverlayList struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	UserID      *int      `json:"userId,omitempty"`
+	Format      string    `json:"format,omitempty"`
+	CardCount   int       `json:"cardCount"` // Number of patched or added cards
+	Created     time.Time `json:"created"`
+}
+
+// Validate checks that the overlay is scoped and that every patch applies to the base cards
+func (o *Overlay) Validate(base services.CardDatabase) error {
+	if o.Name == "" {
+		return fmt.Errorf("overlay name is required")
+	}
+	if (o.UserID == nil) == (o.Format == "") {
+		return fmt.Errorf("overlay must belong to exactly one of a user or a format")
+	}
+	if len(o.Patches) == 0 {
+		return fmt.Errorf("overlay must patch at least one card")
+	}
+	_, err := services.NewOverlayCardDB(base, o.Patches)
+	return err
+}
+
+// SaveOverlay validates the overlay and stores it as the next version of its name
+func SaveOverlay(o *Overlay, base services.CardDatabase) error {
+	if err := o.Validate(base); err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+
+	patchesJSON, err := json.Marshal(o.Patches)
+	if err != nil {
+		return fmt.Errorf("failed to marshal patches: %w", err)
+	}
+
+	var format interface{}
+	if o.Format != "" {
+		format = o.Format
+	}
+
+	err = db.QueryRow(`
+		INSERT INTO card_overlays (name, version, description, user_id, format, patches)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5
+		FROM card_overlays WHERE name = $1
+		RETURNING id, version, created_at`,
+		o.Name, o.Description, o.UserID, format, patchesJSON).Scan(&o.ID, &o.Version, &o.Created)
+	if err != nil {
+		return fmt.Errorf("failed to save overlay: %w", err)
+	}
+
+	return nil
+}
+
+// LoadOverlay loads an overlay version by its unique ID
+func LoadOverlay(id int) (*Overlay, error) {
+	db := database.GetDB()
+
+	row := db.QueryRow(`
+		SELECT id, name, version, description, user_id, COALESCE(format, ''), patches, created_at
+		FROM card_overlays WHERE id = $1`, id)
+	overlay, err := scanOverlay(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("overlay not found with ID: %d", id)
+	}
+	return overlay, err
+}
+
+// LoadLatestOverlay loads the newest version of a named overlay
+func LoadLatestOverlay(name string) (*Overlay, error) {
+	db := database.GetDB()
+
+	row := db.QueryRow(`
+		SELECT id, name, version, description, user_id, COALESCE(format, ''), patches, created_at
+		FROM card_overlays WHERE name = $1
+		ORDER BY version DESC LIMIT 1`, name)
+	overlay, err := scanOverlay(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("overlay not found: %s", name)
+	}
+	return overlay, err
+}
+
+// Resolve loads an overlay by ID, or the latest version if given a name
+func Resolve(identifier string) (*Overlay, error) {
+	if id, err := strconv.Atoi(identifier); err == nil {
+		return LoadOverlay(id)
+	}
+	return LoadLatestOverlay(identifier)
+}
+
+// ListOverlays returns the latest version of each overlay, optionally
+// filtered by owner (userID > 0) or format
+func ListOverlays(userID int, format string) ([]OverlayList, error) {
+	db := database.GetDB()
+
+	query := `
+		SELECT DISTINCT ON (name) id, name, version, description, user_id, COALESCE(format, ''), patches, created_at
+		FROM card_overlays WHERE 1 = 1`
+	args := []interface{}{}
+
+	if userID > 0 {
+		args = append(args, userID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if format != "" {
+		args = append(args, format)
+		query += fmt.Sprintf(" AND format = $%d", len(args))
+	}
+	query += " ORDER BY name, version DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overlays: %w", err)
+	}
+	defer rows.Close()
+
+	overlays := []OverlayList{}
+	for rows.Next() {
+		overlay, err := scanOverlay(rows)
+		if err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, overlay.summary())
+	}
+
+	return overlays, rows.Err()
+}
+
+// ListVersions returns every version of a named overlay, newest first
+func ListVersions(name string) ([]OverlayList, error) {
+	db := database.GetDB()
+
+	rows, err := db.Query(`
+		SELECT id, name, version, description, user_id, COALESCE(format, ''), patches, created_at
+		FROM card_overlays WHERE name = $1 ORDER BY version DESC`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overlay versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []OverlayList{}
+	for rows.Next() {
+		overlay, err := scanOverlay(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, overlay.summary())
+	}
+
+	return versions, rows.Err()
+}
+
+// summary returns the overlay without its patches
+func (o *Overlay) summary() OverlayList {
+	return OverlayList{
+		ID:          o.ID,
+		Name:        o.Name,
+		Version:     o.Version,
+		Description: o.Description,
+		UserID:      o.UserID,
+		Format:      o.Format,
+		CardCount:   len(o.Patches),
+		Created:     o.Created,
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOverlay(row rowScanner) (*Overlay, error) {
+	var overlay Overlay
+	var patchesJSON []byte
+
+	err := row.Scan(&overlay.ID, &overlay.Name, &overlay.Version, &overlay.Description,
+		&overlay.UserID, &overlay.Format, &patchesJSON, &overlay.Created)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan overlay: %w", err)
+	}
+
+	if err := json.Unmarshal(patchesJSON, &overlay.Patches); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal patches: %w", err)
+	}
+
+	return &overlay, nil
+}
+
+// FormatOverlayIDs returns the latest version of each errata overlay of a
+// format, ordered by name
+func FormatOverlayIDs(format string) ([]int, error) {
+	overlays, err := ListOverlays(0, format)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(overlays))
+	for i, overlay := range overlays {
+		ids[i] = overlay.ID
+	}
+	return ids, nil
+}
+
+// Overlay versions never change, so merged card databases are cached by the
+// IDs applied until the base card data is reloaded
+var (
+	cardDBs      = make(map[string]*services.OverlayCardDB)
+	cardDBsMutex sync.Mutex
+)
+
+// CardDB returns the base card database with the given overlay version applied
+func CardDB(id int, base services.CardDatabase) (*services.OverlayCardDB, error) {
+	return StackCardDB([]int{id}, base)
+}
+
+// StackCardDB returns the base card database with the given overlay versions
+// applied in order, so later overlays win. It needs at least one ID.
+func StackCardDB(ids []int, base services.CardDatabase) (*services.OverlayCardDB, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no overlays to apply")
+	}
+
+	cardDBsMutex.Lock()
+	defer cardDBsMutex.Unlock()
+
+	var cardDB *services.OverlayCardDB
+	key := ""
+	for _, id := range ids {
+		key += fmt.Sprintf("%d/", id)
+		if cached, exists := cardDBs[key]; exists {
+			cardDB = cached
+			continue
+		}
+
+		overlay, err := LoadOverlay(id)
+		if err != nil {
+			return nil, err
+		}
+
+		var below services.CardDatabase = base
+		if cardDB != nil {
+			below = cardDB
+		}
+		cardDB, err = services.NewOverlayCardDB(below, overlay.Patches)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply overlay %d: %w", id, err)
+		}
+		cardDBs[key] = cardDB
+	}
+	return cardDB, nil
+}
+
+// ClearCache drops merged card databases; call it after the base card data is reloaded
+func ClearCache() {
+	cardDBsMutex.Lock()
+	defer cardDBsMutex.Unlock()
+	cardDBs = make(map[string]*services.OverlayCardDB)
+}
@@ -31,6 +31,7 @@ const (
 	CounterAdded            LogEventType = "CounterAdded"
 	CounterRemoved          LogEventType = "CounterRemoved"
 	TurnPassed              LogEventType = "TurnPassed"
+	HandMulliganed          LogEventType = "HandMulliganed"
 )
 
 // InstanceID represents a battlefield object instance
@@ -103,8 +104,8 @@ func (e *LogEntry) GetInt(key string) int {
 // GetStringSlice returns a comma-separated string as a slice
 func (e *LogEntry) GetStringSlice(key string) []string {
 	if valueStr, ok := e.Parameters[key]; ok {
-		// Remove quotes and split by comma
-		cleaned := strings.Trim(valueStr, `"`)
+		// Remove quotes, escaped ones included, and split by comma
+		cleaned := strings.Trim(valueStr, `"\`)
 		if cleaned == "" {
 			return []string{}
 		}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetStringSlice(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"plain", `OpeningHandsDrawn p1=TFC-001,TFC-002`, []string{"TFC-001", "TFC-002"}},
+		{"quoted", `OpeningHandsDrawn p1="TFC-001,TFC-002"`, []string{"TFC-001", "TFC-002"}},
+		// InitialLog quotes the list twice, as written since the first server logs
+		{"escaped quotes", `OpeningHandsDrawn p1="\"TFC-001,TFC-002\""`, []string{"TFC-001", "TFC-002"}},
+		{"empty", `OpeningHandsDrawn p1=""`, []string{}},
+		{"missing", `OpeningHandsDrawn p2="TFC-001"`, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseLogContent(tt.line)
+			if err != nil {
+				t.Fatalf("ParseLogContent: %v", err)
+			}
+			if got := entries[0].GetStringSlice("p1"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetStringSlice = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package simulation
+
+import "math"
+
+// confidenceZ is the normal quantile for a 95% confidence interval
+const confidenceZ = 1.96
+
+// Rate is a proportion of games with its 95% Wilson score interval
+type Rate struct {
+	Count int     `json:"count"`
+	Total int     `json:"total"`
+	Rate  float64 `json:"rate"`
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+}
+
+// Report summarises a batch of games from deck A's point of view. Win rates
+// count decided games only; draws are games that reached the turn limit.
+type Report struct {
+	Games        int     `json:"games"`
+	Errors       int     `json:"errors"` // Games that stopped on an error, left out of everything else
+	Draws        int     `json:"draws"`
+	Wins         [2]int  `json:"wins"` // Wins of deck A and deck B
+	WinRate      Rate    `json:"winRate"`
+	FirstPlayer  Rate    `json:"firstPlayer"` // Wins of whichever deck played first
+	AverageTurns float64 `json:"averageTurns"`
+}
+
+// Summarize builds the report for a batch of results
+func Summarize(results []GameResult) *Report {
+	report := &Report{Games: len(results)}
+	firstPlayerWins, totalTurns, played := 0, 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			report.Errors++
+			continue
+		}
+		played++
+		totalTurns += result.Turns
+		switch result.Winner {
+		case 0:
+			report.Draws++
+			continue
+		case 1:
+			firstPlayerWins++
+		}
+		report.Wins[result.WinningDeck()]++
+	}
+
+	decided := report.Wins[0] + report.Wins[1]
+	report.WinRate = NewRate(report.Wins[0], decided)
+	report.FirstPlayer = NewRate(firstPlayerWins, decided)
+	if played > 0 {
+		report.AverageTurns = float64(totalTurns) / float64(played)
+	}
+	return report
+}
+
+// NewRate returns count out of total with its Wilson score interval, which
+// stays within [0, 1] and behaves for small samples and lopsided rates
+func NewRate(count, total int) Rate {
+	rate := Rate{Count: count, Total: total}
+	if total == 0 {
+		rate.High = 1
+		return rate
+	}
+
+	n := float64(total)
+	p := float64(count) / n
+	z2 := confidenceZ * confidenceZ
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := confidenceZ / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+
+	rate.Rate = p
+	rate.Low = math.Max(0, center-margin)
+	rate.High = math.Min(1, center+margin)
+	// The bounds are exact at the ends, without rounding error
+	if count == 0 {
+		rate.Low = 0
+	}
+	if count == total {
+		rate.High = 1
+	}
+	return rate
+}
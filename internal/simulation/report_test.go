@@ -0,0 +1,44 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		count, total int
+		rate         float64
+		low, high    float64
+	}{
+		{name: "no games", count: 0, total: 0, rate: 0, low: 0, high: 1},
+		{name: "none of ten", count: 0, total: 10, rate: 0, low: 0, high: 0.2775},
+		{name: "all of ten", count: 10, total: 10, rate: 1, low: 0.7225, high: 1},
+		{name: "one of one", count: 1, total: 1, rate: 1, low: 0.2065, high: 1},
+		{name: "half of ten", count: 5, total: 10, rate: 0.5, low: 0.2366, high: 0.7634},
+		{name: "half of a hundred", count: 50, total: 100, rate: 0.5, low: 0.4038, high: 0.5962},
+		{name: "81 of 263", count: 81, total: 263, rate: 0.3080, low: 0.2553, high: 0.3662},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := NewRate(tt.count, tt.total)
+			if rate.Count != tt.count || rate.Total != tt.total {
+				t.Errorf("NewRate(%d, %d) counts = %d/%d", tt.count, tt.total, rate.Count, rate.Total)
+			}
+			for _, check := range []struct {
+				field     string
+				got, want float64
+			}{
+				{"Rate", rate.Rate, tt.rate},
+				{"Low", rate.Low, tt.low},
+				{"High", rate.High, tt.high},
+			} {
+				if math.Abs(check.got-check.want) > 1e-4 {
+					t.Errorf("NewRate(%d, %d).%s = %.4f, want %.4f", tt.count, tt.total, check.field, check.got, check.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Package simulation plays batches of bot games between two decks in-process
+// and reports how the matchup went
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"quards/internal/bot"
+	"quards/internal/deck"
+	"quards/internal/game"
+	"quards/internal/lens/core"
+	"quards/internal/lens/services"
+	"quards/internal/parser"
+)
+
+// maxGameActions bounds the actions of one game, in case both bots keep
+// passing without either winning
+const maxGameActions = 5000
+
+// DefaultMaxTurns is the turn after which an undecided game counts as a draw
+const DefaultMaxTurns = 50
+
+// Config describes a batch of games between deck A and deck B
+type Config struct {
+	Decks    [2]*deck.DeckVersion   // Deck A and deck B
+	Bots     [2]string              // Kind of bot playing each deck
+	Games    int                    // Number of games to play
+	Seed     int64                  // Base seed every game's seed is drawn from
+	Workers  int                    // Games played at once, defaults to 1
+	MaxTurns int                    // Turn after which a game is a draw, defaults to DefaultMaxTurns
+	KeepLogs bool                   // Keep each game's log in its result
+	Services *services.LensServices // Lens services with the card database
+}
+
+// GameResult is the outcome of one simulated game
+type GameResult struct {
+	Index     int    `json:"index"`
+	Seed      int    `json:"seed"`
+	FirstDeck int    `json:"firstDeck"` // Deck playing first: 0 for deck A, 1 for deck B
+	Winner    int    `json:"winner"`    // Winning player, or 0 for a draw
+	Turns     int    `json:"turns"`
+	Actions   int    `json:"actions"`
+	Log       string `json:"-"`
+	Err       error  `json:"-"`
+}
+
+// WinningDeck returns the deck that won: 0 for deck A, 1 for deck B, or -1
+// for a draw
+func (r *GameResult) WinningDeck() int {
+	if r.Winner == 0 {
+		return -1
+	}
+	return seatDeck(r.FirstDeck, r.Winner)
+}
+
+// seatDeck returns the deck playing as player: 0 for deck A, 1 for deck B
+func seatDeck(firstDeck, player int) int {
+	if player == 1 {
+		return firstDeck
+	}
+	return 1 - firstDeck
+}
+
+// Run plays the configured games. Every game's seed is drawn from the base
+// seed up front and the bots are seeded from the game's seed, so the results
+// depend only on the config and not on how the games are scheduled. Decks
+// take turns playing first, deck A first in even-numbered games.
+func Run(config Config) ([]GameResult, error) {
+	if config.Decks[0] == nil || config.Decks[1] == nil {
+		return nil, fmt.Errorf("two decks are required")
+	}
+	for _, kind := range config.Bots {
+		if !bot.IsKind(kind) {
+			return nil, fmt.Errorf("unknown bot %q, expected one of %v", kind, bot.Kinds)
+		}
+	}
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxTurns < 1 {
+		config.MaxTurns = DefaultMaxTurns
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	results := make([]GameResult, config.Games)
+	for i := range results {
+		results[i] = GameResult{Index: i, Seed: rng.Intn(math.MaxInt32), FirstDeck: i % 2}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < config.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				play(&config, &results[i])
+			}
+		}()
+	}
+	for i := range results {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, nil
+}
+
+// play plays one game to a win or the turn limit, filling in the result
+func play(config *Config, result *GameResult) {
+	player1Deck := config.Decks[seatDeck(result.FirstDeck, 1)]
+	player2Deck := config.Decks[seatDeck(result.FirstDeck, 2)]
+	logContent := game.InitialLog(player1Deck, player2Deck, result.Seed)
+	entries, err := parser.ParseLogContent(logContent)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to parse initial log: %w", err)
+		return
+	}
+
+	decks := func(player int) (map[string]int, error) {
+		return config.Decks[seatDeck(result.FirstDeck, player)].Cards, nil
+	}
+
+	rng := rand.New(rand.NewSource(int64(result.Seed)))
+	var players [2]bot.Player
+	for player := 1; player <= 2; player++ {
+		kind := config.Bots[seatDeck(result.FirstDeck, player)]
+		players[player-1], err = bot.New(kind, rand.New(rand.NewSource(rng.Int63())))
+		if err != nil {
+			result.Err = err
+			return
+		}
+	}
+
+	svc := config.Services
+	for {
+		state := core.ReadGameState(entries, svc)
+		result.Turns = state.Turn
+		if result.Winner = state.Winner(); result.Winner != 0 || state.Turn > config.MaxTurns {
+			break
+		}
+		if result.Actions >= maxGameActions {
+			result.Err = fmt.Errorf("stopped after %d actions", maxGameActions)
+			break
+		}
+
+		position := bot.NewPosition(entries, state.CurrentPlayer, svc, decks)
+		action, err := players[state.CurrentPlayer-1].ChooseAction(position, bot.ValidActions(position.Entries, position.Services))
+		if err != nil {
+			result.Err = fmt.Errorf("player %d failed to choose an action: %w", state.CurrentPlayer, err)
+			break
+		}
+		// The log is reparsed as the server does after each action, so a
+		// kept log replays to the same game
+		lines := game.ActionLines(entries, action.Type, action.Parameters, state.CurrentPlayer, svc, decks)
+		logContent += strings.Join(lines, "\n") + "\n"
+		entries, err = parser.ParseLogContent(logContent)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to apply %s: %w", action.Type, err)
+			break
+		}
+		result.Actions++
+	}
+
+	if config.KeepLogs {
+		result.Log = logContent
+	}
+}
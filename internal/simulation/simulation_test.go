@@ -0,0 +1,64 @@
+package simulation
+
+import (
+	"reflect"
+	"testing"
+
+	"quards/internal/bot"
+	"quards/internal/deck"
+	"quards/internal/lens/services"
+)
+
+func testServices(t *testing.T) *services.LensServices {
+	t.Helper()
+	cardDB, err := services.NewOverlayCardDB(services.NewInMemoryCardDB(), map[string]services.CardPatch{
+		"CHR-001": {"Name": "Test Hero", "Type": "Character", "Color": "Amber", "Cost": 1, "Inkable": true, "Lore": 1, "Strength": 1, "Willpower": 2},
+		"CHR-002": {"Name": "Test Villain", "Type": "Character", "Color": "Steel", "Cost": 2, "Inkable": true, "Lore": 2, "Strength": 2, "Willpower": 2},
+	})
+	if err != nil {
+		t.Fatalf("NewOverlayCardDB: %v", err)
+	}
+	return &services.LensServices{CardDB: cardDB, Cache: services.NewInMemoryCache()}
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	svc := testServices(t)
+	config := Config{
+		Decks: [2]*deck.DeckVersion{
+			{Name: "Heroes", Cards: map[string]int{"CHR-001": 40, "CHR-002": 20}},
+			{Name: "Villains", Cards: map[string]int{"CHR-001": 20, "CHR-002": 40}},
+		},
+		Bots:     [2]string{bot.KindRandom, bot.KindGreedy},
+		Games:    6,
+		Seed:     7,
+		MaxTurns: 12,
+		KeepLogs: true,
+		Services: svc,
+	}
+
+	config.Workers = 1
+	sequential, err := Run(config)
+	if err != nil {
+		t.Fatalf("Run with 1 worker: %v", err)
+	}
+	config.Workers = 4
+	parallel, err := Run(config)
+	if err != nil {
+		t.Fatalf("Run with 4 workers: %v", err)
+	}
+
+	if len(sequential) != config.Games {
+		t.Fatalf("Run returned %d results, want %d", len(sequential), config.Games)
+	}
+	for _, result := range sequential {
+		if result.Err != nil {
+			t.Fatalf("game %d failed: %v", result.Index, result.Err)
+		}
+		if result.Actions == 0 {
+			t.Fatalf("game %d took no actions", result.Index)
+		}
+	}
+	if !reflect.DeepEqual(sequential, parallel) {
+		t.Errorf("results differ between 1 and 4 workers:\n%+v\n%+v", sequential, parallel)
+	}
+}
@@ -0,0 +1,15 @@
+-- Migration: 003_add_user_roles.sql
+-- Description: Add role-based permissions to users
+-- Created: 2026-10-18
+
+-- Role column: 'player' (default), 'organizer' (runs events), 'admin' (moderates the server)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'player'
+    CHECK (role IN ('player', 'organizer', 'admin'));
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+-- The development user administers local instances
+UPDATE users SET role = 'admin' WHERE id = 1 AND provider = 'dev';
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('003_add_user_roles') 
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,12 @@
+-- Migration: 004_add_deck_formats.sql
+-- Description: Store the target format each deck is validated against
+-- Created: 2026-10-18
+
+-- Format ID (see data/formats and the built-in 'core'/'infinity' formats).
+-- Existing decks default to 'infinity', which accepts every set.
+ALTER TABLE decks ADD COLUMN IF NOT EXISTS format TEXT NOT NULL DEFAULT 'infinity';
+CREATE INDEX IF NOT EXISTS idx_decks_format ON decks(format);
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('004_add_deck_formats') 
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,40 @@
+-- Migration: 005_add_deck_versions.sql
+-- Description: Record every deck save and pin games to the deck versions they used
+-- Created: 2026-10-18
+
+-- Deck versions table: one row per save, numbered per deck
+CREATE TABLE IF NOT EXISTS deck_versions (
+    id SERIAL PRIMARY KEY,
+    deck_id INTEGER NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    format TEXT NOT NULL DEFAULT 'infinity',
+    cards JSONB NOT NULL, -- CardID -> Count mapping at the time of the save
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (deck_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deck_versions_deck_id ON deck_versions(deck_id);
+
+-- Existing decks start at version 1 with their current contents
+INSERT INTO deck_versions (deck_id, version, name, description, format, cards, created_at)
+SELECT id, 1, name, description, format, cards, modified_at FROM decks
+WHERE NOT EXISTS (SELECT 1 FROM deck_versions v WHERE v.deck_id = decks.id);
+
+-- Games remember the exact deck versions they were created with
+ALTER TABLE games ADD COLUMN IF NOT EXISTS player1_deck_version_id INTEGER REFERENCES deck_versions(id) ON DELETE SET NULL;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS player2_deck_version_id INTEGER REFERENCES deck_versions(id) ON DELETE SET NULL;
+
+-- Best effort: pin existing games to the only version we know about
+UPDATE games SET player1_deck_version_id = v.id
+FROM decks d JOIN deck_versions v ON v.deck_id = d.id AND v.version = 1
+WHERE d.name = games.player1_deck AND games.player1_deck_version_id IS NULL;
+
+UPDATE games SET player2_deck_version_id = v.id
+FROM decks d JOIN deck_versions v ON v.deck_id = d.id AND v.version = 1
+WHERE d.name = games.player2_deck AND games.player2_deck_version_id IS NULL;
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('005_add_deck_versions') 
+ON CONFLICT (version) DO NOTHING;
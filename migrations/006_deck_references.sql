@@ -0,0 +1,30 @@
+-- Migration: 006_deck_references.sql
+-- Description: Reference decks from games by ID and support soft-deleting referenced decks
+-- Created: 2026-10-18
+
+-- Games reference decks by foreign key. player1_deck/player2_deck keep the deck
+-- name as it was when the game was created, matching the GameStarted log entry.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS player1_deck_id INTEGER REFERENCES decks(id) ON DELETE RESTRICT;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS player2_deck_id INTEGER REFERENCES decks(id) ON DELETE RESTRICT;
+
+-- Convert existing name references
+UPDATE games SET player1_deck_id = d.id FROM decks d
+WHERE d.name = games.player1_deck AND games.player1_deck_id IS NULL;
+
+UPDATE games SET player2_deck_id = d.id FROM decks d
+WHERE d.name = games.player2_deck AND games.player2_deck_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_games_player1_deck_id ON games(player1_deck_id);
+CREATE INDEX IF NOT EXISTS idx_games_player2_deck_id ON games(player2_deck_id);
+
+-- Decks still used by games are soft-deleted instead of removed
+ALTER TABLE decks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_decks_deleted_at ON decks(deleted_at);
+
+-- Names only need to be unique among decks that haven't been deleted
+ALTER TABLE decks DROP CONSTRAINT IF EXISTS decks_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_decks_active_name ON decks(name) WHERE deleted_at IS NULL;
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('006_deck_references') 
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,27 @@
+-- Migration: 007_add_cards.sql
+-- Description: Store card data in the database and notify servers when it changes
+-- Created: 2026-10-18
+
+-- Each row holds one card record in the canonical (lorcana-api) JSON shape
+CREATE TABLE IF NOT EXISTS cards (
+    id TEXT PRIMARY KEY, -- Unique_ID, e.g. "ARI-001"
+    data JSONB NOT NULL,
+    modified_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Servers LISTEN on cards_changed and reload their card cache
+CREATE OR REPLACE FUNCTION notify_cards_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('cards_changed', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS cards_changed ON cards;
+CREATE TRIGGER cards_changed
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON cards
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_cards_changed();
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('007_add_cards') 
+ON CONFLICT (version) DO NOTHING;
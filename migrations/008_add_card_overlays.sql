@@ -0,0 +1,27 @@
+-- Migration: 008_add_card_overlays.sql
+-- Description: Card errata and custom card overlays, pinned by games
+-- Created: 2026-10-18
+
+-- Overlay versions are immutable: saving an overlay adds a new version so
+-- games that used an earlier one replay with the same cards
+CREATE TABLE IF NOT EXISTS card_overlays (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    description TEXT DEFAULT '',
+    user_id INTEGER REFERENCES users(id), -- Owner of a personal (playtest) overlay
+    format TEXT, -- Format an errata overlay applies to
+    patches JSONB NOT NULL, -- Unique_ID -> card fields to add or override
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (name, version),
+    CHECK ((user_id IS NULL) <> (format IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_overlays_user_id ON card_overlays(user_id);
+CREATE INDEX IF NOT EXISTS idx_card_overlays_format ON card_overlays(format);
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS card_overlay_id INTEGER REFERENCES card_overlays(id) ON DELETE RESTRICT;
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('008_add_card_overlays') 
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,21 @@
+-- Migration: 009_add_game_seats.sql
+-- Description: Bind game seats to users for online play
+-- Created: 2026-10-18
+
+-- Once any seat of a game is taken, only the seated user may act for it.
+-- An unclaimed seat can be claimed with its invite token, or by anyone if open.
+CREATE TABLE IF NOT EXISTS game_seats (
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    seat INTEGER NOT NULL CHECK (seat IN (1, 2)),
+    user_id INTEGER REFERENCES users(id),
+    invite_token TEXT NOT NULL UNIQUE,
+    open BOOLEAN NOT NULL DEFAULT false,
+    joined_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (game_id, seat)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_seats_user_id ON game_seats(user_id);
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('009_add_game_seats') 
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,25 @@
+-- Migration: 010_add_spectator_settings.sql
+-- Description: Delay what spectators see of live games and allow caster views
+-- Created: 2026-10-18
+
+-- Spectators follow a seated game delayed by whole turns, minutes, or both
+-- (whichever shows less). Casters may additionally see both hands when the
+-- game allows it.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS spectator_delay_turns INTEGER NOT NULL DEFAULT 0
+    CHECK (spectator_delay_turns >= 0);
+ALTER TABLE games ADD COLUMN IF NOT EXISTS spectator_delay_minutes INTEGER NOT NULL DEFAULT 0
+    CHECK (spectator_delay_minutes >= 0);
+ALTER TABLE games ADD COLUMN IF NOT EXISTS caster_view BOOLEAN NOT NULL DEFAULT false;
+
+-- When each appended log step was recorded, for time-based delays. Steps of
+-- the initial log count as recorded when the game was created.
+CREATE TABLE IF NOT EXISTS game_step_times (
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    step INTEGER NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, step)
+);
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('010_add_spectator_settings') 
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,29 @@
+-- Migration: 011_add_game_takebacks.sql
+-- Description: Audit trail for takebacks that cut a game log back to an earlier step
+-- Created: 2026-10-18
+
+-- A takeback keeps the log entries it removes, so the game's full history
+-- can be reconstructed. In games where both seats are held by different
+-- users it stays pending until the opponent approves it.
+CREATE TABLE IF NOT EXISTS game_takebacks (
+    id SERIAL PRIMARY KEY,
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    from_step INTEGER NOT NULL, -- Log length when requested
+    to_step INTEGER NOT NULL,   -- Log length after the takeback
+    removed_log TEXT NOT NULL,  -- Entries taken back
+    requested_by INTEGER REFERENCES users(id),
+    resolved_by INTEGER REFERENCES users(id),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'rejected', 'expired')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    CHECK (to_step < from_step)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_takebacks_game_id ON game_takebacks(game_id);
+
+-- At most one takeback per game awaits approval
+CREATE UNIQUE INDEX IF NOT EXISTS idx_game_takebacks_pending ON game_takebacks(game_id) WHERE status = 'pending';
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('011_add_game_takebacks')
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,14 @@
+-- Migration: 012_add_game_forks.sql
+-- Description: Record which game and step a forked game branched from
+-- Created: 2026-10-18
+
+-- A fork copies the first fork_step log entries of its parent. Deleting the
+-- parent keeps its forks as standalone games.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS parent_game_id INTEGER REFERENCES games(id) ON DELETE SET NULL;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS fork_step INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_games_parent_game_id ON games(parent_game_id);
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('012_add_game_forks')
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Migration: 013_add_bot_seats.sql
+-- Description: Let a built-in bot hold a game seat
+-- Created: 2026-10-18
+
+-- A seat with a bot is played by the server: random, greedy or search.
+-- Bot seats have no user and cannot be claimed.
+ALTER TABLE game_seats ADD COLUMN IF NOT EXISTS bot TEXT;
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('013_add_bot_seats')
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,15 @@
+-- Migration: 014_add_game_overlay_stack.sql
+-- Description: Pin every card overlay a game is played with, in order
+-- Created: 2026-10-18
+
+-- A game applies the errata overlays of its decks' formats, then the overlay
+-- requested at creation (card_overlay_id), so decks play with the cards they
+-- were checked against. Overlay versions are never deleted.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS card_overlay_ids INTEGER[] NOT NULL DEFAULT '{}';
+
+UPDATE games SET card_overlay_ids = ARRAY[card_overlay_id]
+WHERE card_overlay_id IS NOT NULL AND card_overlay_ids = '{}';
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('014_add_game_overlay_stack')
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Migration: 015_add_game_creators.sql
+-- Description: Remember which signed-in user created a game
+-- Created: 2026-10-18
+
+-- The creator may manage a game's free seats, such as handing one to a bot,
+-- even before anyone sits down. Games created anonymously have none.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS created_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- Record this migration
+INSERT INTO schema_migrations (version) VALUES ('015_add_game_creators')
+ON CONFLICT (version) DO NOTHING;
@@ -5,12 +5,24 @@ let isPlaying = false;
 let playInterval;
 let availableActions = [];
 let currentGameID = null;
+let loadedLogSteps = 0; // Number of log entries in the last loaded navigation data
+let gameEvents = null;
+
+// Casters open the viewer with ?view=caster to see both hands on the spectator delay
+function withView(url) {
+    const view = new URLSearchParams(window.location.search).get('view');
+    if (!view) {
+        return url;
+    }
+    return `${url}${url.includes('?') ? '&' : '?'}view=${encodeURIComponent(view)}`;
+}
 
 // Load game data on page load
 window.addEventListener('load', async () => {
     console.log('Page load event triggered');
     console.log('Loading card database...');
     await loadCardDatabase();
+    await acceptSeatInvite();
     console.log('Card database loaded, loading game steps...');
     await loadGameSteps();
     
@@ -21,11 +33,68 @@ window.addEventListener('load', async () => {
     } else {
         console.log('No game steps to render');
     }
+    
+    subscribeToGameEvents();
 });
 
+// Take the seat an invite link points at, then drop the token from the URL
+async function acceptSeatInvite() {
+    const urlParams = new URLSearchParams(window.location.search);
+    const gameID = urlParams.get('game');
+    const seat = urlParams.get('seat');
+    const token = urlParams.get('invite');
+    if (!gameID || !seat || !token) {
+        return;
+    }
+    
+    try {
+        const response = await fetch(`/api/games/${gameID}/seats/${seat}/claim`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ token })
+        });
+        const result = await response.json();
+        if (!response.ok) {
+            throw new Error(result.error || 'Failed to accept invite');
+        }
+        
+        urlParams.delete('seat');
+        urlParams.delete('invite');
+        window.history.replaceState(null, '', `${window.location.pathname}?${urlParams}`);
+    } catch (error) {
+        console.error('Failed to accept invite:', error);
+        alert(`Failed to accept invite: ${error.message}`);
+    }
+}
+
+// Follow moves made by other players as they happen
+function subscribeToGameEvents() {
+    if (!currentGameID || !window.EventSource) {
+        return;
+    }
+    
+    gameEvents = new EventSource(withView(`/api/games/${currentGameID}/events`));
+    
+    const onUpdate = async (event) => {
+        const update = JSON.parse(event.data);
+        if (update.step === loadedLogSteps) {
+            return; // Already showing this state, e.g. after our own action
+        }
+        await loadGameSteps();
+        await renderCurrentStep();
+    };
+    
+    gameEvents.addEventListener('snapshot', onUpdate);
+    gameEvents.addEventListener('append', onUpdate);
+    gameEvents.addEventListener('reset', onUpdate);
+    gameEvents.onerror = () => console.warn('Game event stream interrupted, reconnecting...');
+}
+
 async function loadCardDatabase() {
     try {
-        const response = await fetch('/cards.lorcana-api.json');
+        const response = await fetch('/cards.json');
         const cards = await response.json();
         
         // Create lookup map by Unique_ID
@@ -48,7 +117,7 @@ async function fetchGameStateForStep(stepNumber) {
             return null;
         }
         
-        const response = await fetch(`/api/games/${currentGameID}/state?step=${stepNumber}`);
+        const response = await fetch(withView(`/api/games/${currentGameID}/state?step=${stepNumber}`));
         
         if (!response.ok) {
             console.error('Failed to fetch game state data');
@@ -82,7 +151,7 @@ async function loadGameSteps() {
         }
         
         currentGameID = gameID;
-        const apiUrl = `/api/games/${gameID}/navigation`;
+        const apiUrl = withView(`/api/games/${gameID}/navigation`);
         
         console.log('Fetching navigation data from API URL:', apiUrl);
         const response = await fetch(apiUrl);
@@ -100,6 +169,7 @@ async function loadGameSteps() {
         }
         
         const allSteps = data.data || [];
+        loadedLogSteps = allSteps.length;
         
         // Filter to only show player choice actions using server-provided flag
         const playerChoiceSteps = allSteps.filter(step => step.isPlayerChoice);
@@ -177,7 +247,7 @@ async function loadAvailableActions() {
         // Calculate actions based on historical context (up to original step number)
         const currentGameStep = gameSteps[currentStep];
         const stepNumber = currentGameStep ? currentGameStep.originalStepNumber : currentStep + 1;
-        const apiUrl = `/api/games/${currentGameID}/actions?step=${stepNumber}`;
+        const apiUrl = withView(`/api/games/${currentGameID}/actions?step=${stepNumber}`);
         const response = await fetch(apiUrl);
         const data = await response.json();
         
@@ -417,53 +487,37 @@ async function truncateGameFromAction(action) {
             throw new Error('No game loaded');
         }
         
-        console.log('Truncating game from action:', action);
+        console.log('Taking game back to action:', action);
         
-        if (!confirm(`Are you sure you want to truncate the game at step ${currentStep + 1}? This will permanently delete all steps after this point.`)) {
+        if (!confirm(`Take the game back to step ${currentStep + 1}? In games with an opponent they must approve the takeback.`)) {
             return;
         }
         
-        // Get the game log up to the current step
-        const logUpToCurrentStep = gameSteps.slice(0, currentStep + 1);
-        
-        // Convert steps back to log format
-        const logEntries = logUpToCurrentStep.map(step => {
-            return JSON.stringify({
-                turn: step.turn,
-                player: step.player,
-                action: step.action,
-                parameters: step.parameters
-            });
-        });
-        
-        const logContent = logEntries.join('\n') + '\n';
-        
-        // Update the current game with truncated log
-        const response = await fetch(`/api/games/${currentGameID}/truncate`, {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-            },
-            body: JSON.stringify({
-                logContent: logContent
-            })
+        // Keep every log entry up to and including the current step
+        const toStep = gameSteps[currentStep].step + 1;
+        const response = await fetch(`/api/games/${currentGameID}/undo?toStep=${toStep}`, {
+            method: 'POST'
         });
         
         const result = await response.json();
         
         if (!response.ok || result.error) {
-            throw new Error(result.error || 'Failed to truncate game');
+            throw new Error(result.error || 'Failed to take back moves');
         }
         
-        console.log('Game truncated successfully:', result);
+        console.log('Takeback recorded:', result);
+        if (result.data.status === 'pending') {
+            alert('Takeback requested. It will be applied once your opponent approves it.');
+            return;
+        }
         
-        // Reload the game to show the truncated state
+        // Reload the game to show the earlier state
         await loadGameSteps();
         await renderCurrentStep();
         
     } catch (error) {
-        console.error('Failed to truncate game:', error);
-        alert(`Failed to truncate game: ${error.message}`);
+        console.error('Failed to take back moves:', error);
+        alert(`Failed to take back moves: ${error.message}`);
     }
 }
 
@@ -475,37 +529,10 @@ async function forkGameFromAction(action) {
         
         console.log('Forking game from action:', action);
         
-        // Create a new game name for the fork
-        const timestamp = Date.now();
-        const forkGameName = `${currentGameName}-fork-${timestamp}`;
-        
-        // Get the game log up to the current step
-        const logUpToCurrentStep = gameSteps.slice(0, currentStep + 1);
-        
-        // Convert steps back to log format
-        const logEntries = logUpToCurrentStep.map(step => {
-            return JSON.stringify({
-                turn: step.turn,
-                player: step.player,
-                action: step.action,
-                parameters: step.parameters
-            });
-        });
-        
-        const logContent = logEntries.join('\n') + '\n';
-        
-        // Create the forked game
-        const response = await fetch('/api/games', {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-            },
-            body: JSON.stringify({
-                name: forkGameName,
-                player1Deck: 'unknown', // Will be extracted from log
-                player2Deck: 'unknown', // Will be extracted from log
-                logContent: logContent
-            })
+        // The fork keeps every log entry up to and including the current step
+        const step = gameSteps[currentStep].step + 1;
+        const response = await fetch(`/api/games/${currentGameID}/fork?step=${step}`, {
+            method: 'POST'
         });
         
         const result = await response.json();
@@ -517,7 +544,7 @@ async function forkGameFromAction(action) {
         console.log('Forked game created:', result);
         
         // Redirect to the new forked game
-        window.location.href = `/?game=${forkGameName}`;
+        window.location.href = `/?game=${result.data.id}`;
         
     } catch (error) {
         console.error('Failed to fork game:', error);
@@ -653,7 +680,8 @@ function renderPlayerZones(playerZones, playerId) {
     
     // Update hand count and render face-down cards
     // Hand is now an array of card objects
-    const handCount = playerZones.hand ? playerZones.hand.length : 0;
+    // Hidden hands come back empty with only their size in hand_count
+    const handCount = playerZones.hand_count ?? (playerZones.hand ? playerZones.hand.length : 0);
     console.log(`${playerId} hand count:`, handCount, 'hand data:', playerZones.hand);
     
     const handCountElement = document.getElementById(`${playerId}-hand-count`);
@@ -664,7 +692,12 @@ function renderPlayerZones(playerZones, playerId) {
         console.error(`Hand count element not found: ${playerId}-hand-count`);
     }
     
-    renderHandCards(`${playerId}-hand`, playerZones.hand || []);
+    const hand = playerZones.hand || [];
+    if (hand.length < handCount) {
+        renderFaceDownCards(`${playerId}-hand`, handCount);
+    } else {
+        renderHandCards(`${playerId}-hand`, hand);
+    }
     
     // Render battlefield (face-up cards with instances and exhaustion)
     renderBattlefieldCards(`${playerId}-battlefield`, playerZones.in_play || []);
@@ -887,7 +920,7 @@ async function renderGameHistory() {
     
     try {
         // Fetch history descriptions from server
-        const response = await fetch(`/api/games/${currentGameID}/history`);
+        const response = await fetch(withView(`/api/games/${currentGameID}/history`));
         if (!response.ok) {
             throw new Error(`Failed to fetch history: ${response.statusText}`);
         }
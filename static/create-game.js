@@ -185,7 +185,8 @@ async function createGame() {
     const gameData = {
         player1Deck: player1Deck,
         player2Deck: player2Deck,
-        seed: gameSeed || null
+        seed: gameSeed || null,
+        bot: document.getElementById('player2Bot').value
     };
     
     try {
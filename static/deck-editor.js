@@ -1,11 +1,12 @@
 let isEditMode = false;
-let originalDeckName = null;
+let editDeckId = null;
 let cardDatabase = {};
 
 // Load data on page load
 window.addEventListener('load', async () => {
     parseUrlParams();
     await loadCardDatabase();
+    await loadFormats();
     setupEventListeners();
     
     if (isEditMode) {
@@ -29,26 +30,43 @@ async function loadCardDatabase() {
     }
 }
 
+async function loadFormats() {
+    try {
+        const response = await fetch('/api/formats');
+        const data = await response.json();
+        const select = document.getElementById('deckFormat');
+        
+        select.innerHTML = data.data
+            .map(format => `<option value="${format.id}">${format.name}</option>`)
+            .join('');
+        select.value = 'infinity';
+    } catch (error) {
+        console.error('Failed to load formats:', error);
+    }
+}
+
 function parseUrlParams() {
     const urlParams = new URLSearchParams(window.location.search);
-    const deckName = urlParams.get('deck');
+    const deckId = urlParams.get('id');
     
-    if (deckName) {
+    if (deckId) {
         isEditMode = true;
-        originalDeckName = deckName;
-        document.getElementById('pageTitle').textContent = `Edit Deck: ${deckName}`;
-        document.getElementById('deckName').value = deckName;
+        editDeckId = deckId;
     }
 }
 
 async function loadDeckForEditing() {
     try {
-        const response = await fetch(`/api/decks/${originalDeckName}`);
+        const response = await fetch(`/api/decks/${editDeckId}`);
         const data = await response.json();
         const deck = data.data;
         
+        document.getElementById('pageTitle').textContent = `Edit Deck: ${deck.name}`;
         document.getElementById('deckName').value = deck.name;
         document.getElementById('deckDescription').value = deck.description || '';
+        if (deck.format) {
+            document.getElementById('deckFormat').value = deck.format;
+        }
         
         // Convert cards object to text format (from IDs to names)
         const cardListText = Object.entries(deck.cards)
@@ -86,7 +104,7 @@ function updateCardCount() {
     
     // Update counter
     document.getElementById('cardCount').textContent = totalCount;
-    document.getElementById('cardCount').style.color = totalCount === 60 ? '#4CAF50' : '#ff6666';
+    document.getElementById('cardCount').style.color = totalCount >= 60 ? '#4CAF50' : '#ff6666';
     
     // Show validation errors
     const errorContainer = document.getElementById('validationErrors');
@@ -152,8 +170,8 @@ function parseCardList(text) {
         totalCount += quantity;
     });
     
-    if (totalCount !== 60 && totalCount > 0) {
-        errors.push(`Total cards must be exactly 60 (currently ${totalCount})`);
+    if (totalCount < 60 && totalCount > 0) {
+        errors.push(`Deck must have at least 60 cards (currently ${totalCount})`);
     }
     
     return { cards, totalCount, errors };
@@ -188,6 +206,7 @@ async function saveDeck() {
     const deckData = {
         name: deckName,
         description: deckDescription,
+        format: document.getElementById('deckFormat').value,
         cards: cards
     };
     
@@ -200,7 +219,7 @@ async function saveDeck() {
         
         let response;
         if (isEditMode) {
-            response = await fetch(`/api/decks/${originalDeckName}`, {
+            response = await fetch(`/api/decks/${editDeckId}`, {
                 method: 'PUT',
                 headers: {
                     'Content-Type': 'application/json'
@@ -222,6 +241,9 @@ async function saveDeck() {
             window.location.href = '/decks.html';
         } else {
             const error = await response.json();
+            if (Array.isArray(error.data) && error.data.length > 0) {
+                showServerViolations(error.data);
+            }
             throw new Error(error.error || 'Failed to save deck');
         }
         
@@ -234,4 +256,13 @@ async function saveDeck() {
         saveBtn.textContent = originalText;
         saveBtn.disabled = false;
     }
-}
\ No newline at end of file
+}
+
+// Show deckbuilding rule violations reported by the server
+function showServerViolations(violations) {
+    const errorContainer = document.getElementById('validationErrors');
+    const errorList = document.getElementById('errorList');
+    
+    errorList.innerHTML = violations.map(violation => `<li>${violation.detail}</li>`).join('');
+    errorContainer.style.display = 'block';
+}
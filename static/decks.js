@@ -7,6 +7,7 @@ window.addEventListener('load', async () => {
     await loadCardDatabase();
     await loadDecks();
     renderDecks();
+    renderCompareOptions();
     setupEventListeners();
 });
 
@@ -64,9 +65,9 @@ function renderDecks() {
                 <span>Modified: ${modifiedDate}</span>
             </div>
             <div class="deck-actions">
-                <button class="btn" onclick="viewDeck('${deck.name}')">View</button>
-                <button class="btn secondary" onclick="editDeck('${deck.name}')">Edit</button>
-                <button class="btn danger" onclick="deleteDeck('${deck.name}')">Delete</button>
+                <button class="btn" onclick="viewDeck(${deck.id})">View</button>
+                <button class="btn secondary" onclick="editDeck(${deck.id})">Edit</button>
+                <button class="btn danger" onclick="deleteDeck(${deck.id})">Delete</button>
             </div>
         `;
         
@@ -75,9 +76,9 @@ function renderDecks() {
 }
 
 
-async function viewDeck(deckName) {
+async function viewDeck(deckId) {
     try {
-        const response = await fetch(`/api/decks/${deckName}`);
+        const response = await fetch(`/api/decks/${deckId}`);
         const data = await response.json();
         const deck = data.data;
         
@@ -96,7 +97,7 @@ async function viewDeck(deckName) {
             gamesButton.id = 'gamesWithDeckBtn';
             gamesButton.className = 'btn secondary';
             gamesButton.style.marginTop = '10px';
-            gamesButton.onclick = () => viewGamesWithDeck(deck.name);
+            gamesButton.onclick = () => viewGamesWithDeck(deck.id);
             modalDescription.parentNode.insertBefore(gamesButton, modalCards);
         }
         gamesButton.textContent = `View Games with ${deck.name}`;
@@ -145,17 +146,19 @@ async function viewDeck(deckName) {
     }
 }
 
-function editDeck(deckName) {
-    window.location.href = `/deck-editor.html?deck=${deckName}`;
+function editDeck(deckId) {
+    window.location.href = `/deck-editor.html?id=${deckId}`;
 }
 
-async function deleteDeck(deckName) {
+async function deleteDeck(deckId) {
+    const deck = decks.find(d => d.id === deckId);
+    const deckName = deck ? deck.name : deckId;
     if (!confirm(`Are you sure you want to delete the deck "${deckName}"?`)) {
         return;
     }
     
     try {
-        const response = await fetch(`/api/decks/${deckName}`, {
+        const response = await fetch(`/api/decks/${deckId}`, {
             method: 'DELETE'
         });
         
@@ -192,11 +195,101 @@ function setupEventListeners() {
     
     // Create deck button
     document.getElementById('createDeckBtn').addEventListener('click', createDeck);
+    
+    // Compare button
+    document.getElementById('compareBtn').addEventListener('click', compareDecks);
+}
+
+
+function renderCompareOptions() {
+    ['compareDeckA', 'compareDeckB'].forEach((selectId, index) => {
+        const select = document.getElementById(selectId);
+        select.innerHTML = '';
+        decks.forEach(deck => {
+            const option = document.createElement('option');
+            option.value = deck.id;
+            option.textContent = deck.name;
+            select.appendChild(option);
+        });
+        if (decks.length > index) {
+            select.selectedIndex = index;
+        }
+    });
+}
+
+async function compareDecks() {
+    const a = document.getElementById('compareDeckA').value;
+    const b = document.getElementById('compareDeckB').value;
+    const result = document.getElementById('compareResult');
+    
+    if (!a || !b) {
+        result.innerHTML = '<p style="color: #888;">Select two decks to compare.</p>';
+        return;
+    }
+    
+    try {
+        const response = await fetch(`/api/decks/compare?a=${a}&b=${b}`);
+        const data = await response.json();
+        
+        if (!response.ok) {
+            result.innerHTML = `<p style="color: #f44336;">${data.error}</p>`;
+            return;
+        }
+        
+        renderComparison(data.data);
+    } catch (error) {
+        console.error('Failed to compare decks:', error);
+        result.innerHTML = '<p style="color: #f44336;">Failed to compare decks</p>';
+    }
+}
+
+function renderComparison(comparison) {
+    const nameA = comparison.a.name;
+    const nameB = comparison.b.name;
+    
+    const cardRows = cards => cards.map(card => {
+        const deltaClass = card.delta > 0 ? 'delta-up' : card.delta < 0 ? 'delta-down' : '';
+        const delta = card.delta > 0 ? `+${card.delta}` : card.delta;
+        return `<tr><td>${card.name}</td><td>${card.a}</td><td>${card.b}</td><td class="${deltaClass}">${delta}</td></tr>`;
+    }).join('');
+    
+    const cardTable = (title, cards) => `
+        <h3>${title} (${cards.length})</h3>
+        <table class="compare-table">
+            <tr><th>Card</th><th>${nameA}</th><th>${nameB}</th><th>Delta</th></tr>
+            ${cardRows(cards)}
+        </table>
+    `;
+    
+    const curveRows = comparison.costCurve.map(point =>
+        `<tr><td>${point.cost}</td><td>${point.a}</td><td>${point.b}</td></tr>`
+    ).join('');
+    
+    const colorRows = Object.entries(comparison.colors).map(([color, counts]) =>
+        `<tr><td>${color}</td><td>${counts.a}</td><td>${counts.b}</td></tr>`
+    ).join('');
+    
+    document.getElementById('compareResult').innerHTML = `
+        <h3>Cost Curve</h3>
+        <table class="compare-table">
+            <tr><th>Cost</th><th>${nameA}</th><th>${nameB}</th></tr>
+            ${curveRows}
+        </table>
+        <h3>Colors</h3>
+        <table class="compare-table">
+            <tr><th>Color</th><th>${nameA}</th><th>${nameB}</th></tr>
+            ${colorRows}
+        </table>
+        ${cardTable('Different Counts', comparison.changed)}
+        ${cardTable(`Only in ${nameA}`, comparison.onlyA)}
+        ${cardTable(`Only in ${nameB}`, comparison.onlyB)}
+        ${cardTable('Shared', comparison.shared)}
+    `;
 }
 
 
-function viewGamesWithDeck(deckName) {
-    window.location.href = `/games.html?deck=${deckName}`;
+function viewGamesWithDeck(deckId) {
+    window.location.href = `/games.html?deck=${deckId}`;
 }
 
 function createCachedImage(src, alt) {
@@ -37,7 +37,7 @@ async function loadDecks() {
         const deckFilter = document.getElementById('deckFilter');
         decks.forEach(deck => {
             const option = document.createElement('option');
-            option.value = deck.name;
+            option.value = deck.id;
             option.textContent = deck.name;
             deckFilter.appendChild(option);
         });
@@ -45,6 +45,10 @@ async function loadDecks() {
         // Set initial deck filter if from URL
         if (currentFilters.deck) {
             deckFilter.value = currentFilters.deck;
+            const filteredDeck = decks.find(d => String(d.id) === currentFilters.deck);
+            if (filteredDeck) {
+                document.getElementById('filteredMessage').innerHTML = `Showing games using deck: <strong>${filteredDeck.name}</strong>`;
+            }
         }
         
         console.log(`Loaded ${decks.length} decks`);
@@ -108,7 +112,7 @@ function applyFilters() {
     filteredGames = games.filter(game => {
         // Deck filter
         if (currentFilters.deck) {
-            if (game.player1Deck !== currentFilters.deck && game.player2Deck !== currentFilters.deck) {
+            if (String(game.player1DeckId) !== currentFilters.deck && String(game.player2DeckId) !== currentFilters.deck) {
                 return false;
             }
         }
@@ -116,10 +120,10 @@ function applyFilters() {
         // Player filter
         if (currentFilters.player) {
             const playerNum = parseInt(currentFilters.player);
-            if (playerNum === 1 && game.player1Deck !== currentFilters.deck) {
+            if (playerNum === 1 && String(game.player1DeckId) !== currentFilters.deck) {
                 return false;
             }
-            if (playerNum === 2 && game.player2Deck !== currentFilters.deck) {
+            if (playerNum === 2 && String(game.player2DeckId) !== currentFilters.deck) {
                 return false;
             }
         }